@@ -1,6 +1,13 @@
 package hal
 
-import "context"
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+)
 
 type Automation interface {
 	// Name is a friendly name for the automation, used in logs and stats.
@@ -16,20 +23,47 @@ type Automation interface {
 }
 
 type AutomationConfig struct {
-	action   func(ctx context.Context, trigger EntityInterface)
-	entities Entities
-	name     string
+	action          func(ctx context.Context, trigger EntityInterface)
+	entities        Entities
+	eventAction     func(ctx context.Context, event homeassistant.Event)
+	events          []EventTrigger
+	name            string
+	scheduledAction func(ctx context.Context, scheduledTime time.Time)
+	schedules       []Schedule
+	triggers        []*stateTrigger
 }
 
 func NewAutomation() *AutomationConfig {
 	return &AutomationConfig{}
 }
 
+// Entities returns the entities passed to WithEntities, followed by the
+// entities of any state triggers.
 func (c *AutomationConfig) Entities() Entities {
-	return c.entities
+	if len(c.triggers) == 0 {
+		return c.entities
+	}
+
+	entities := slices.Clone(c.entities)
+
+	for _, trigger := range c.triggers {
+		if !slices.ContainsFunc(entities, func(e EntityInterface) bool {
+			return e.GetID() == trigger.Entity.GetID()
+		}) {
+			entities = append(entities, trigger.Entity)
+		}
+	}
+
+	return entities
 }
 
 func (c *AutomationConfig) Action(ctx context.Context, trigger EntityInterface) {
+	if c.action == nil {
+		logger.ErrorContext(ctx, "Automation has entities but no action")
+
+		return
+	}
+
 	c.action(ctx, trigger)
 }
 
@@ -47,12 +81,113 @@ func (c *AutomationConfig) WithAction(action func(ctx context.Context, trigger E
 	return c
 }
 
+// WithStateChangeAction is like WithAction, for actions that need the state
+// change that triggered them, e.g. to compare the old and new state or to see
+// who caused the change.
+func (c *AutomationConfig) WithStateChangeAction(action func(ctx context.Context, change StateChange)) *AutomationConfig {
+	c.action = func(ctx context.Context, _ EntityInterface) {
+		change, _ := GetStateChangeFromContext(ctx)
+		action(ctx, change)
+	}
+
+	if c.name == "" {
+		c.name = getShortFunctionName(action)
+	}
+
+	return c
+}
+
+// WithEventAction sets the action for runs from WithEventTrigger. It is given
+// the event, e.g. to read its data with EventData.Decode.
+func (c *AutomationConfig) WithEventAction(action func(ctx context.Context, event homeassistant.Event)) *AutomationConfig {
+	c.eventAction = action
+
+	if c.name == "" {
+		c.name = getShortFunctionName(action)
+	}
+
+	return c
+}
+
 func (c *AutomationConfig) WithEntities(entities ...EntityInterface) *AutomationConfig {
 	c.entities = entities
 
 	return c
 }
 
+// WithTriggers runs the automation only for state changes that match one of
+// the triggers, instead of for every change to the entity. Entities passed to
+// WithEntities that have no trigger still run it on every change.
+func (c *AutomationConfig) WithTriggers(triggers ...StateTrigger) *AutomationConfig {
+	for _, trigger := range triggers {
+		c.triggers = append(c.triggers, &stateTrigger{StateTrigger: trigger})
+	}
+
+	return c
+}
+
+// WithSchedule also runs the automation on the given schedules, e.g.
+// hal.MustAt(6, 45, hal.Weekdays()...) or hal.Every(5 * time.Minute). Scheduled
+// runs call the action set with WithScheduledAction.
+func (c *AutomationConfig) WithSchedule(schedules ...Schedule) *AutomationConfig {
+	c.schedules = append(c.schedules, schedules...)
+
+	return c
+}
+
+// WithScheduledAction sets the action for runs from WithSchedule. It is given
+// the time the run was scheduled for.
+func (c *AutomationConfig) WithScheduledAction(action func(ctx context.Context, scheduledTime time.Time)) *AutomationConfig {
+	c.scheduledAction = action
+
+	if c.name == "" {
+		c.name = getShortFunctionName(action)
+	}
+
+	return c
+}
+
+func (c *AutomationConfig) Schedules() []Schedule {
+	return c.schedules
+}
+
+func (c *AutomationConfig) ScheduledAction(ctx context.Context, scheduledTime time.Time) {
+	if c.scheduledAction == nil {
+		logger.ErrorContext(ctx, "Automation has schedules but no scheduled action")
+
+		return
+	}
+
+	c.scheduledAction(ctx, scheduledTime)
+}
+
+// WithEventTrigger also runs the automation when Home Assistant fires an event
+// of the given type, e.g. "zha_event", and filter, if not nil, returns true for
+// it. Events call the action set with WithEventAction.
+func (c *AutomationConfig) WithEventTrigger(eventType string, filter func(event homeassistant.Event) bool) *AutomationConfig {
+	c.events = append(c.events, EventTrigger{EventType: eventType, Filter: filter})
+
+	return c
+}
+
+func (c *AutomationConfig) EventTriggers() []EventTrigger {
+	return c.events
+}
+
+func (c *AutomationConfig) EventAction(ctx context.Context, event homeassistant.Event) {
+	if c.eventAction == nil {
+		logger.ErrorContext(ctx, "Automation has event triggers but no event action")
+
+		return
+	}
+
+	c.eventAction(ctx, event)
+}
+
+func (c *AutomationConfig) stateTriggers() []*stateTrigger {
+	return c.triggers
+}
+
 func (c *AutomationConfig) WithName(name string) *AutomationConfig {
 	c.name = name
 
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dansimau/hal/store"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// NewHealthCmd creates the health command
+func NewHealthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "health",
+		Short: "Display entities with health problems",
+		Long: `Display the entities flagged by the watchdog in its latest check: sensors
+that have not reported within their window, and entities with a low battery.`,
+		Example: `  hal health                # Show flagged entities`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHealthCommand()
+		},
+	}
+
+	return cmd
+}
+
+func runHealthCommand() error {
+	// Open database connection using default path
+	db, err := store.Open("sqlite.db")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	var findings []store.HealthFinding
+
+	err = db.Order("entity_id, kind").Find(&findings).Error
+	if err != nil {
+		return fmt.Errorf("failed to query health findings: %w", err)
+	}
+
+	return printHealthTable(findings)
+}
+
+func printHealthTable(findings []store.HealthFinding) error {
+	if len(findings) == 0 {
+		fmt.Println("No health problems found")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header("Entity ID", "Problem", "Details", "Battery", "Last Reported", "Since")
+
+	for _, finding := range findings {
+		battery := "-"
+		if finding.BatteryLevel != nil {
+			battery = strconv.FormatFloat(*finding.BatteryLevel, 'f', -1, 64) + "%"
+		}
+
+		err := table.Append(
+			finding.EntityID,
+			finding.Kind,
+			finding.Message,
+			battery,
+			formatHealthTime(finding.LastReported),
+			formatHealthTime(finding.Since),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to append row: %w", err)
+		}
+	}
+
+	if err := table.Render(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nLast checked: %s\n", formatHealthTime(findings[0].CheckedAt))
+
+	return nil
+}
+
+func formatHealthTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Local().Format("2006-01-02 15:04:05")
+}
@@ -26,4 +26,5 @@ func init() {
 	rootCmd.AddCommand(commands.NewEntitiesCmd())
 	rootCmd.AddCommand(commands.NewPruneCmd())
 	rootCmd.AddCommand(commands.NewEventsCmd())
+	rootCmd.AddCommand(commands.NewHealthCmd())
 }
\ No newline at end of file
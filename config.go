@@ -16,6 +16,10 @@ type Config struct {
 	DatabasePath      string              `yaml:"databasePath"`
 	ReconnectInterval time.Duration       `yaml:"reconnectInterval"`
 
+	// TimeZone is the IANA time zone that schedules run in, e.g.
+	// "Europe/London". Defaults to the system time zone if unset.
+	TimeZone string `yaml:"timeZone"`
+
 	// PingInterval is how often to send a heartbeat ping to Home Assistant to
 	// keep the connection active. Defaults to 30s if unset.
 	PingInterval time.Duration `yaml:"pingInterval"`
@@ -24,6 +28,10 @@ type Config struct {
 	// treating the connection as stale and reconnecting. Should be larger than
 	// PingInterval. Defaults to 60s if unset.
 	ReadTimeout time.Duration `yaml:"readTimeout"`
+
+	// Health configures the watchdog that flags sensors that have stopped
+	// reporting or are low on battery.
+	Health HealthConfig `yaml:"health"`
 }
 
 type HomeAssistantConfig struct {
@@ -32,6 +40,23 @@ type HomeAssistantConfig struct {
 	UserID string `yaml:"userId"`
 }
 
+type HealthConfig struct {
+	// CheckInterval is how often entities are checked. Defaults to 5m if unset.
+	CheckInterval time.Duration `yaml:"checkInterval"`
+
+	// BatteryThreshold is the battery percentage below which an entity is
+	// flagged. Defaults to 20 if unset.
+	BatteryThreshold float64 `yaml:"batteryThreshold"`
+
+	// StaleAfter is how long any registered entity may go without reporting
+	// before it is flagged. Disabled if unset, since many entities (e.g.
+	// switches) only report when they change.
+	StaleAfter time.Duration `yaml:"staleAfter"`
+
+	// Entities overrides StaleAfter for individual entity IDs.
+	Entities map[string]time.Duration `yaml:"entities"`
+}
+
 type LocationConfig struct {
 	Latitude  float64 `yaml:"lat"`
 	Longitude float64 `yaml:"lng"`
@@ -1,12 +1,16 @@
 package hal
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/dansimau/hal/hassws"
+	"github.com/dansimau/hal/homeassistant"
 	"github.com/dansimau/hal/logger"
 	"github.com/dansimau/hal/metrics"
 	"github.com/dansimau/hal/perf"
@@ -26,11 +30,40 @@ type Connection struct {
 	automations map[string][]Automation
 	entities    map[string]EntityInterface
 
+	// eventsMutex guards the fields below. Listeners for event types other
+	// than state_changed are subscribed to when connected, and again after
+	// every reconnect.
+	eventsMutex          sync.Mutex
+	eventListeners       map[string][]eventListener
+	nextEventListenerID  int
+	subscribedEventTypes map[string]bool // nil until connected
+	subscriptionsID      int             // incremented on every connect
+
+	availabilityListeners []availabilityListener
+	stateObservers        map[string][]stateObserver
+
+	// dispatchCh queues state changes and events for the dispatch goroutine,
+	// which applies them and runs automations in the order they were
+	// received.
+	dispatchCh chan func()
+
 	// Lock to serialize state updates and ensure automations fire in order.
 	mutex sync.RWMutex
 
 	homeAssistant  *hassws.Client
 	metricsService *metrics.Service
+	watchdog       *Watchdog
+
+	// clock is guarded by clockMutex rather than mutex, so that entities can
+	// read it from automations.
+	clockMutex sync.RWMutex
+	clock      clock.Clock
+
+	// Schedules for scheduled automations, guarded by mutex. Schedules run in
+	// location.
+	schedules        []*scheduledRun
+	schedulesStarted bool
+	location         *time.Location
 
 	*SunTimes
 
@@ -67,26 +100,69 @@ func NewConnection(cfg Config) *Connection {
 	// Set the database on the global logger
 	logger.SetDefaultDatabase(db)
 
+	location := time.Local
+	if cfg.TimeZone != "" {
+		location, err = time.LoadLocation(cfg.TimeZone)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	// Set reconnect interval with default
 	reconnectInterval := cfg.ReconnectInterval
 	if reconnectInterval == 0 {
 		reconnectInterval = 10 * time.Second
 	}
 
-	return &Connection{
+	conn := &Connection{
 		config:         cfg,
 		db:             db,
 		homeAssistant:  api,
 		metricsService: metrics.NewService(db),
+		clock:          clock.New(),
+		location:       location,
 
-		automations: make(map[string][]Automation),
-		entities:    make(map[string]EntityInterface),
+		automations:    make(map[string][]Automation),
+		entities:       make(map[string]EntityInterface),
+		eventListeners: make(map[string][]eventListener),
+		stateObservers: make(map[string][]stateObserver),
+		dispatchCh:     make(chan func(), dispatchQueueSize),
 
 		SunTimes: NewSunTimes(cfg.Location),
 
 		shutdownCh:        make(chan struct{}),
 		reconnectInterval: reconnectInterval,
 	}
+
+	conn.watchdog = newWatchdog(conn, cfg.Health)
+
+	return conn
+}
+
+// WithClock can be used to pass in a mock clock for testing. It must be called
+// before Start.
+func (h *Connection) WithClock(c clock.Clock) *Connection {
+	h.clockMutex.Lock()
+	defer h.clockMutex.Unlock()
+
+	h.clock = c
+	h.SunTimes.WithClock(c)
+
+	return h
+}
+
+// getClock returns the clock set with WithClock.
+func (h *Connection) getClock() clock.Clock {
+	h.clockMutex.RLock()
+	defer h.clockMutex.RUnlock()
+
+	return h.clock
+}
+
+// Watchdog returns the watchdog that checks registered entities for sensors
+// that have stopped reporting or are low on battery.
+func (h *Connection) Watchdog() *Watchdog {
+	return h.watchdog
 }
 
 func (h *Connection) CallService(msg hassws.CallServiceRequest) (hassws.CallServiceResponse, error) {
@@ -116,6 +192,14 @@ func (h *Connection) RegisterAutomations(automations ...Automation) {
 		for _, entity := range automation.Entities() {
 			h.automations[entity.GetID()] = append(h.automations[entity.GetID()], automation)
 		}
+
+		if scheduled, ok := automation.(ScheduledAutomation); ok {
+			h.registerSchedules(scheduled)
+		}
+
+		if eventAutomation, ok := automation.(EventAutomation); ok {
+			h.registerEventTriggers(eventAutomation)
+		}
 	}
 }
 
@@ -148,6 +232,10 @@ func (h *Connection) connect() error {
 		return fmt.Errorf("failed to sync initial states: %w", err)
 	}
 
+	if err := h.subscribeEventListeners(); err != nil {
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
 	return nil
 }
 
@@ -158,6 +246,11 @@ func (h *Connection) Start() error {
 	h.metricsService.Start()
 	logger.StartDefault()
 
+	go h.dispatch()
+	go h.watchdog.run()
+
+	h.startSchedules()
+
 	// Create disconnection signal channel
 	disconnectedCh := make(chan struct{}, 1)
 
@@ -215,6 +308,8 @@ func (h *Connection) Close() {
 		// Signal shutdown to stop reconnection loop
 		close(h.shutdownCh)
 
+		h.stopSchedules()
+
 		// Close WebSocket connection
 		h.homeAssistant.Close()
 
@@ -239,6 +334,9 @@ func (h *Connection) syncStates() error {
 		return err
 	}
 
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
 	for _, state := range states {
 		entity, ok := h.entities[state.EntityID]
 		if !ok {
@@ -247,15 +345,46 @@ func (h *Connection) syncStates() error {
 
 		logger.Debug("Setting initial state", state.EntityID, "State", state)
 
-		entity.SetState(state)
+		// States may have changed while disconnected, so they are applied the
+		// same way as state changes.
+		h.notifyStateObservers(state.EntityID, state)
+		h.setEntityState(entity, state)
 	}
 
 	return nil
 }
 
-// Process incoming state change events. Dispatch state change to the relevant
-// entity and fire any automations listening for state changes to this entity.
+// StateChangeEvent queues an incoming state change to be applied to the
+// relevant entity and to fire any automations listening for state changes to
+// this entity. State observers are notified straight away.
 func (h *Connection) StateChangeEvent(event hassws.EventMessage) {
+	if event.Event.EventData.NewState != nil {
+		h.notifyStateObservers(event.Event.EventData.EntityID, *event.Event.EventData.NewState)
+	}
+
+	h.enqueue(func() {
+		h.applyStateChange(event)
+	})
+}
+
+// setEntityState sets the state of an entity and notifies the availability
+// listeners if its availability changed. The caller must hold mutex.
+func (h *Connection) setEntityState(entity EntityInterface, state homeassistant.State) {
+	current := entity.GetState()
+
+	entity.SetState(state)
+
+	// An entity whose state has not been received yet is not considered to
+	// have changed availability.
+	if current.State != "" && isAvailableState(current.State) != isAvailableState(state.State) {
+		h.notifyAvailabilityChange(entity, isAvailableState(state.State))
+	}
+}
+
+// applyStateChange applies a state change to the relevant entity and fires any
+// automations listening for state changes to it. It is called on the dispatch
+// goroutine.
+func (h *Connection) applyStateChange(event hassws.EventMessage) {
 	defer perf.Timer(func(timeTaken time.Duration) {
 		logger.Debug("Tick processing time", event.Event.EventData.EntityID, "duration", timeTaken)
 		// Record tick processing time metric
@@ -292,7 +421,7 @@ func (h *Connection) StateChangeEvent(event hassws.EventMessage) {
 		return
 	}
 
-	entity.SetState(newState)
+	h.setEntityState(entity, newState)
 
 	// Update database asynchronously
 	entityID := event.Event.EventData.EntityID
@@ -305,21 +434,301 @@ func (h *Connection) StateChangeEvent(event hassws.EventMessage) {
 		}).Error
 	})
 
-	// Prevent loops by not running automations that originate from hal
-	if event.Event.Context.UserID == h.config.HomeAssistant.UserID {
-		logger.Debug("Skipping automation from own action", event.Event.EventData.EntityID)
+	oldState := current
+	if event.Event.EventData.OldState != nil {
+		oldState = *event.Event.EventData.OldState
+	}
 
-		return
+	change := StateChange{
+		EntityID:  entityID,
+		OldState:  oldState,
+		NewState:  newState,
+		Context:   event.Event.Context,
+		TimeFired: h.parseTimeFired(event.Event.TimeFired),
+	}
+
+	// Prevent loops by not running automations that originate from hal. State
+	// triggers still see the change, so that a pending For is cancelled if hal
+	// changes the state back.
+	ownChange := event.Event.Context.UserID == h.config.HomeAssistant.UserID
+	if ownChange {
+		logger.Debug("Skipping automation from own action", event.Event.EventData.EntityID)
 	}
 
 	// Dispatch automations
-	for _, automation := range h.automations[event.Event.EventData.EntityID] {
-		// Create context with tracing metadata
-		ctx := NewAutomationContext(event.Event.EventData.EntityID, automation.Name())
-
-		logger.InfoContext(ctx, "Running automation")
-		// Record automation triggered metric
-		h.metricsService.RecordCounter(store.MetricTypeAutomationTriggered, event.Event.EventData.EntityID, automation.Name())
-		automation.Action(ctx, entity)
+	for _, automation := range h.automations[entityID] {
+		if !h.evaluateStateTriggers(automation, entity, change, ownChange) || ownChange {
+			continue
+		}
+
+		h.runAutomation(automation, entity, change)
+	}
+}
+
+// runAutomation runs an automation that was triggered by a state change to the
+// given entity. The caller must hold mutex.
+func (h *Connection) runAutomation(automation Automation, entity EntityInterface, change StateChange) {
+	// Create context with tracing metadata
+	ctx := NewAutomationContext(change.EntityID, automation.Name())
+	ctx = WithStateChange(ctx, change)
+
+	logger.InfoContext(ctx, "Running automation")
+	// Record automation triggered metric
+	h.metricsService.RecordCounter(store.MetricTypeAutomationTriggered, change.EntityID, automation.Name())
+	automation.Action(ctx, entity)
+}
+
+// parseTimeFired parses the time an event was fired, falling back to now if
+// it is missing or malformed.
+func (h *Connection) parseTimeFired(timeFired string) time.Time {
+	if t, err := time.Parse(time.RFC3339Nano, timeFired); err == nil {
+		return t
+	}
+
+	return h.getClock().Now()
+}
+
+type availabilityListener struct {
+	id      int
+	handler func(ctx context.Context, entity EntityInterface, available bool)
+}
+
+// OnAvailabilityChange registers a handler that is called when a registered
+// entity becomes unavailable (its state changes to "unavailable" or "unknown")
+// or becomes available again. Unlike automations, handlers are also called for
+// changes caused by hal itself. It returns a function that removes the handler.
+func (h *Connection) OnAvailabilityChange(handler func(ctx context.Context, entity EntityInterface, available bool)) (remove func()) {
+	h.eventsMutex.Lock()
+	defer h.eventsMutex.Unlock()
+
+	h.nextEventListenerID++
+	id := h.nextEventListenerID
+
+	h.availabilityListeners = append(h.availabilityListeners, availabilityListener{id: id, handler: handler})
+
+	return func() {
+		h.eventsMutex.Lock()
+		defer h.eventsMutex.Unlock()
+
+		h.availabilityListeners = slices.DeleteFunc(h.availabilityListeners, func(l availabilityListener) bool {
+			return l.id == id
+		})
+	}
+}
+
+// notifyAvailabilityChange calls the availability listeners. The caller must
+// hold mutex.
+func (h *Connection) notifyAvailabilityChange(entity EntityInterface, available bool) {
+	h.eventsMutex.Lock()
+	listeners := slices.Clone(h.availabilityListeners)
+	h.eventsMutex.Unlock()
+
+	ctx := NewAutomationContext(entity.GetID(), "")
+
+	if available {
+		logger.InfoContext(ctx, "Entity became available")
+	} else {
+		logger.WarnContext(ctx, "Entity became unavailable")
+	}
+
+	for _, listener := range listeners {
+		listener.handler(ctx, entity, available)
+	}
+}
+
+type eventListener struct {
+	id      int
+	handler func(homeassistant.Event)
+
+	// observer is true for listeners called as soon as an event is received,
+	// rather than on the dispatch goroutine.
+	observer bool
+}
+
+// OnEvent registers a handler for Home Assistant events of the given type,
+// e.g. "mobile_app_notification_action". Handlers are called in the order
+// events are received, serialized with state changes and automations, so they
+// must not block. It returns a function that removes the handler.
+func (h *Connection) OnEvent(eventType string, handler func(homeassistant.Event)) (remove func()) {
+	return h.addEventListener(eventType, handler, false)
+}
+
+// observeEvent registers a handler that is called with every event of the
+// given type as soon as it is received, without holding mutex, so that it is
+// called even while an automation is running. Handlers must not block. It
+// returns a function that removes the handler.
+func (h *Connection) observeEvent(eventType string, handler func(homeassistant.Event)) (remove func()) {
+	return h.addEventListener(eventType, handler, true)
+}
+
+func (h *Connection) addEventListener(eventType string, handler func(homeassistant.Event), observer bool) (remove func()) {
+	h.eventsMutex.Lock()
+
+	h.nextEventListenerID++
+	id := h.nextEventListenerID
+
+	h.eventListeners[eventType] = append(h.eventListeners[eventType], eventListener{id: id, handler: handler, observer: observer})
+
+	// If not connected yet, the subscription is made on connect. The event
+	// type is marked as subscribed before subscribing, so that it is only
+	// subscribed to once.
+	subscribe := h.subscribedEventTypes != nil && !h.subscribedEventTypes[eventType]
+	if subscribe {
+		h.subscribedEventTypes[eventType] = true
+	}
+
+	subscriptionsID := h.subscriptionsID
+
+	// Subscribing waits for Home Assistant to reply, so it is done without
+	// holding eventsMutex, which is needed to deliver events.
+	h.eventsMutex.Unlock()
+
+	if subscribe {
+		if err := h.subscribeEventType(eventType); err != nil {
+			logger.Error("Error subscribing to events, will retry on reconnect", "", "event_type", eventType, "error", err)
+
+			h.eventsMutex.Lock()
+			if h.subscriptionsID == subscriptionsID {
+				delete(h.subscribedEventTypes, eventType)
+			}
+			h.eventsMutex.Unlock()
+		}
+	}
+
+	return func() {
+		h.eventsMutex.Lock()
+		defer h.eventsMutex.Unlock()
+
+		h.eventListeners[eventType] = slices.DeleteFunc(h.eventListeners[eventType], func(l eventListener) bool {
+			return l.id == id
+		})
+	}
+}
+
+// subscribeEventListeners subscribes to every event type that has listeners,
+// on a new connection.
+func (h *Connection) subscribeEventListeners() error {
+	h.eventsMutex.Lock()
+
+	h.subscriptionsID++
+	h.subscribedEventTypes = make(map[string]bool)
+
+	eventTypes := make([]string, 0, len(h.eventListeners))
+	for eventType := range h.eventListeners {
+		eventTypes = append(eventTypes, eventType)
+		h.subscribedEventTypes[eventType] = true
+	}
+
+	h.eventsMutex.Unlock()
+
+	// If one fails, the connection is retried and every event type is
+	// subscribed to again.
+	for _, eventType := range eventTypes {
+		if err := h.subscribeEventType(eventType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// subscribeEventType subscribes to events of the given type. It must not be
+// called with eventsMutex held, since it waits for Home Assistant to reply.
+func (h *Connection) subscribeEventType(eventType string) error {
+	return h.homeAssistant.SubscribeEvents(eventType, h.handleEvent)
+}
+
+// handleEvent calls the observers for the event's type, and queues the event
+// to be dispatched to the other listeners.
+func (h *Connection) handleEvent(event hassws.EventMessage) {
+	h.eventsMutex.Lock()
+	observers := slices.Clone(h.eventListeners[event.Event.EventType])
+	h.eventsMutex.Unlock()
+
+	for _, observer := range observers {
+		if observer.observer {
+			observer.handler(event.Event)
+		}
+	}
+
+	h.enqueue(func() {
+		h.eventsMutex.Lock()
+		listeners := slices.Clone(h.eventListeners[event.Event.EventType])
+		h.eventsMutex.Unlock()
+
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+
+		for _, listener := range listeners {
+			if !listener.observer {
+				listener.handler(event.Event)
+			}
+		}
+	})
+}
+
+// dispatchQueueSize is the number of state changes and events that can be
+// queued while automations run before the subscriptions stop being read.
+const dispatchQueueSize = 8192
+
+// enqueue queues fn to be run on the dispatch goroutine.
+func (h *Connection) enqueue(fn func()) {
+	select {
+	case h.dispatchCh <- fn:
+	case <-h.shutdownCh:
+	}
+}
+
+// dispatch runs queued state changes and events in order until the connection
+// is closed. Running them on one goroutine, rather than on the goroutines that
+// read the subscriptions, means an automation that waits for a state change or
+// event (e.g. Script.RunAndWait) does not stop it from being received.
+func (h *Connection) dispatch() {
+	for {
+		select {
+		case fn := <-h.dispatchCh:
+			fn()
+		case <-h.shutdownCh:
+			return
+		}
+	}
+}
+
+type stateObserver struct {
+	id      int
+	handler func(homeassistant.State)
+}
+
+// observeState registers a handler that is called with every new state of the
+// entity as soon as it is received, before it is applied and without holding
+// mutex, so that it is called even while an automation is running. Handlers
+// must not block. It returns a function that removes the handler.
+func (h *Connection) observeState(entityID string, handler func(homeassistant.State)) (remove func()) {
+	h.eventsMutex.Lock()
+	defer h.eventsMutex.Unlock()
+
+	h.nextEventListenerID++
+	id := h.nextEventListenerID
+
+	h.stateObservers[entityID] = append(h.stateObservers[entityID], stateObserver{id: id, handler: handler})
+
+	return func() {
+		h.eventsMutex.Lock()
+		defer h.eventsMutex.Unlock()
+
+		h.stateObservers[entityID] = slices.DeleteFunc(h.stateObservers[entityID], func(o stateObserver) bool {
+			return o.id == id
+		})
+	}
+}
+
+// notifyStateObservers calls the state observers for an entity.
+func (h *Connection) notifyStateObservers(entityID string, state homeassistant.State) {
+	h.eventsMutex.Lock()
+	observers := slices.Clone(h.stateObservers[entityID])
+	h.eventsMutex.Unlock()
+
+	for _, observer := range observers {
+		observer.handler(state)
 	}
 }
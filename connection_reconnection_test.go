@@ -1,6 +1,8 @@
 package hal
 
 import (
+	"context"
+	"encoding/json"
 	"sync"
 	"testing"
 	"time"
@@ -193,9 +195,9 @@ func TestStateSyncAfterReconnect(t *testing.T) {
 	// Wait for subscription to be re-established
 	waitForEventSubscription(t, server, 1)
 
-	// The reconnection process calls syncStates(), but since the mock server
-	// returns empty state list, we can't directly test state re-sync.
-	// This test verifies that reconnection completes successfully.
+	// The reconnection process calls syncStates(), which reapplies the
+	// states the server holds. This test verifies that reconnection completes
+	// successfully.
 
 	// Verify connection works by sending new event
 	server.SendEvent(homeassistant.Event{
@@ -213,6 +215,56 @@ func TestStateSyncAfterReconnect(t *testing.T) {
 	}, func() {})
 }
 
+func TestAvailabilityChangeDuringOutage(t *testing.T) {
+	conn, server, cleanup := newFastReconnectClientServer(t)
+	defer cleanup()
+
+	sensor := NewBinarySensor("binary_sensor.door")
+	conn.RegisterEntities(sensor)
+
+	var (
+		mutex   sync.Mutex
+		changes []bool
+	)
+
+	conn.OnAvailabilityChange(func(_ context.Context, _ EntityInterface, available bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		changes = append(changes, available)
+	})
+
+	server.SendEvent(homeassistant.Event{
+		EventData: homeassistant.EventData{
+			EntityID: "binary_sensor.door",
+			NewState: &homeassistant.State{EntityID: "binary_sensor.door", State: "off"},
+		},
+	})
+
+	waitFor(t, "initial state", func() bool {
+		return sensor.GetState().State == "off"
+	}, func() {})
+
+	assert.NilError(t, server.DisconnectClient())
+
+	// The sensor goes away while disconnected, so no state change is sent.
+	server.SetState(homeassistant.State{EntityID: "binary_sensor.door", State: "unavailable"})
+
+	waitForReconnection(t, conn, 1)
+
+	waitFor(t, "availability change from resync", func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(changes) == 1 && !changes[0]
+	}, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		t.Logf("Availability changes: %v", changes)
+	})
+}
+
 func TestMultipleDisconnectReconnectCycles(t *testing.T) {
 	conn, server, cleanup := newFastReconnectClientServer(t)
 	defer cleanup()
@@ -361,3 +413,63 @@ func TestReconnectionAttemptCounter(t *testing.T) {
 	waitForEventSubscription(t, server, 1)
 	assert.Equal(t, 1, conn.GetReconnectAttempts())
 }
+
+func TestEventListenersRestoredAfterReconnect(t *testing.T) {
+	conn, server, cleanup := newFastReconnectClientServer(t)
+	defer cleanup()
+
+	var (
+		mutex    sync.Mutex
+		received []string
+	)
+
+	conn.OnEvent("custom_event", func(event homeassistant.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		received = append(received, string(event.EventData.Raw))
+	})
+
+	// One subscription for state changes and one for the custom event
+	waitForEventSubscription(t, server, 2)
+
+	sendCustomEvent := func(data string) {
+		server.SendEvent(homeassistant.Event{
+			EventType: "custom_event",
+			EventData: homeassistant.EventData{Raw: json.RawMessage(data)},
+		})
+	}
+
+	receivedCount := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(received)
+	}
+
+	sendCustomEvent(`{"n":1}`)
+
+	waitFor(t, "custom event received", func() bool {
+		return receivedCount() == 1
+	}, func() {})
+
+	// Disconnect
+	assert.NilError(t, server.DisconnectClient())
+
+	// Wait for reconnection
+	waitForReconnection(t, conn, 1)
+
+	// Both subscriptions are re-established
+	waitForEventSubscription(t, server, 2)
+
+	sendCustomEvent(`{"n":2}`)
+
+	waitFor(t, "custom event after reconnection", func() bool {
+		return receivedCount() == 2
+	}, func() {})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	assert.DeepEqual(t, received, []string{`{"n":1}`, `{"n":2}`})
+}
@@ -287,3 +287,122 @@ func TestNilStateLeavesStateUnchanged(t *testing.T) {
 	assert.Equal(t, "on", testEntity.GetState().State)
 	assert.Equal(t, int32(2), automationTriggered.Load())
 }
+
+func TestOnAvailabilityChange(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	sensor := hal.NewBinarySensor("binary_sensor.door")
+	conn.RegisterEntities(sensor)
+
+	var (
+		mutex   sync.Mutex
+		changes []bool
+	)
+
+	remove := conn.OnAvailabilityChange(func(_ context.Context, entity hal.EntityInterface, available bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		assert.Equal(t, entity.GetID(), "binary_sensor.door")
+		changes = append(changes, available)
+	})
+
+	for _, state := range []string{"off", "on", "unavailable", "unknown", "off"} {
+		server.SendEvent(homeassistant.Event{
+			EventData: homeassistant.EventData{
+				EntityID: "binary_sensor.door",
+				NewState: &homeassistant.State{EntityID: "binary_sensor.door", State: state},
+			},
+		})
+	}
+
+	testutil.WaitFor(t, "verify availability changes", func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(changes) == 2
+	}, func() {
+		spew.Dump(sensor.GetState())
+	})
+
+	remove()
+
+	server.SendEvent(homeassistant.Event{
+		EventData: homeassistant.EventData{
+			EntityID: "binary_sensor.door",
+			NewState: &homeassistant.State{EntityID: "binary_sensor.door", State: "unavailable"},
+		},
+	})
+
+	testutil.WaitFor(t, "verify sensor unavailable", func() bool {
+		return !sensor.IsAvailable()
+	}, func() {
+		spew.Dump(sensor.GetState())
+	})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// The first state is the initial state, not a change, and the handler was
+	// removed before the last one.
+	assert.DeepEqual(t, changes, []bool{false, true})
+	assert.Assert(t, !sensor.IsOn() && !sensor.IsOff())
+}
+
+func TestStateChangePassedToAutomation(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	light := hal.NewLight("light.kitchen")
+	conn.RegisterEntities(light)
+
+	changes := make(chan hal.StateChange, 1)
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("test.automation").
+			WithEntities(light).
+			WithStateChangeAction(func(_ context.Context, change hal.StateChange) {
+				changes <- change
+			}),
+	)
+
+	timeFired := time.Date(2026, 7, 17, 23, 0, 15, 0, time.UTC)
+
+	server.SendEvent(homeassistant.Event{
+		EventData: homeassistant.EventData{
+			EntityID: "light.kitchen",
+			OldState: &homeassistant.State{EntityID: "light.kitchen", State: "off"},
+			NewState: &homeassistant.State{
+				EntityID:   "light.kitchen",
+				State:      "on",
+				Attributes: map[string]any{"brightness": float64(255)},
+			},
+		},
+		TimeFired: timeFired.Format(time.RFC3339Nano),
+		Context: homeassistant.EventMessageContext{
+			ID:       "context-id",
+			ParentID: "parent-id",
+			UserID:   "other-user",
+		},
+	})
+
+	select {
+	case change := <-changes:
+		assert.Equal(t, change.EntityID, "light.kitchen")
+		assert.Equal(t, change.OldState.State, "off")
+		assert.Equal(t, change.NewState.State, "on")
+		assert.Assert(t, change.StateChanged())
+		assert.DeepEqual(t, change.ChangedAttributes(), []string{"brightness"})
+		assert.Equal(t, change.Context.ParentID, "parent-id")
+		assert.Equal(t, change.Context.UserID, "other-user")
+		assert.Assert(t, change.TimeFired.Equal(timeFired))
+	case <-time.After(3 * time.Second):
+		t.Fatal("automation was not triggered")
+	}
+}
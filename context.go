@@ -1,6 +1,13 @@
 package hal
 
-import "context"
+import (
+	"context"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/dansimau/hal/homeassistant"
+)
 
 type contextKey string
 
@@ -10,8 +17,78 @@ const (
 
 	// AutomationNameKey is the context key for storing the automation name
 	AutomationNameKey contextKey = "automation_name"
+
+	// StateChangeKey is the context key for storing the triggering state change
+	StateChangeKey contextKey = "state_change"
+
+	// ScheduledTimeKey is the context key for storing the time a scheduled
+	// automation was scheduled to run
+	ScheduledTimeKey contextKey = "scheduled_time"
+
+	// EventKey is the context key for storing the triggering Home Assistant
+	// event
+	EventKey contextKey = "event"
 )
 
+// StateChange is a state change that triggered an automation.
+type StateChange struct {
+	EntityID string
+
+	// OldState is the state before the change. It is empty if the entity had
+	// no state yet.
+	OldState homeassistant.State
+	NewState homeassistant.State
+
+	// Context identifies what caused the change, e.g. the user ID of a user
+	// who changed it from the Home Assistant UI, or the parent ID of an
+	// automation that changed it.
+	Context homeassistant.EventMessageContext
+
+	TimeFired time.Time
+}
+
+// StateChanged returns true if the state changed, rather than only its
+// attributes.
+func (c StateChange) StateChanged() bool {
+	return c.OldState.State != c.NewState.State
+}
+
+// ChangedAttributes returns the names of the attributes that were added,
+// removed or changed, in sorted order.
+func (c StateChange) ChangedAttributes() []string {
+	var changed []string
+
+	for name, value := range c.NewState.Attributes {
+		if oldValue, ok := c.OldState.Attributes[name]; !ok || !reflect.DeepEqual(oldValue, value) {
+			changed = append(changed, name)
+		}
+	}
+
+	for name := range c.OldState.Attributes {
+		if _, ok := c.NewState.Attributes[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	slices.Sort(changed)
+
+	return changed
+}
+
+// WithStateChange returns a copy of ctx carrying the state change
+func WithStateChange(ctx context.Context, change StateChange) context.Context {
+	return context.WithValue(ctx, StateChangeKey, change)
+}
+
+// GetStateChangeFromContext extracts the state change that triggered an
+// automation from context. The second return value is false if the automation
+// was not triggered by a state change.
+func GetStateChangeFromContext(ctx context.Context) (StateChange, bool) {
+	change, ok := ctx.Value(StateChangeKey).(StateChange)
+
+	return change, ok
+}
+
 // NewAutomationContext creates a context with automation metadata
 func NewAutomationContext(triggerEntityID string, automationName string) context.Context {
 	ctx := context.Background()
@@ -35,3 +112,33 @@ func GetAutomationNameFromContext(ctx context.Context) string {
 	}
 	return ""
 }
+
+// WithScheduledTime returns a copy of ctx carrying the time a scheduled
+// automation was scheduled to run
+func WithScheduledTime(ctx context.Context, scheduledTime time.Time) context.Context {
+	return context.WithValue(ctx, ScheduledTimeKey, scheduledTime)
+}
+
+// GetScheduledTimeFromContext extracts the time a scheduled automation was
+// scheduled to run from context. The second return value is false if the
+// automation was not run by a schedule.
+func GetScheduledTimeFromContext(ctx context.Context) (time.Time, bool) {
+	scheduledTime, ok := ctx.Value(ScheduledTimeKey).(time.Time)
+
+	return scheduledTime, ok
+}
+
+// WithEvent returns a copy of ctx carrying the Home Assistant event that
+// triggered an automation
+func WithEvent(ctx context.Context, event homeassistant.Event) context.Context {
+	return context.WithValue(ctx, EventKey, event)
+}
+
+// GetEventFromContext extracts the Home Assistant event that triggered an
+// automation from context. The second return value is false if the automation
+// was not triggered by an event trigger.
+func GetEventFromContext(ctx context.Context) (homeassistant.Event, bool) {
+	event, ok := ctx.Value(EventKey).(homeassistant.Event)
+
+	return event, ok
+}
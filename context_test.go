@@ -2,7 +2,10 @@ package hal
 
 import (
 	"context"
+	"slices"
 	"testing"
+
+	"github.com/dansimau/hal/homeassistant"
 )
 
 func TestNewAutomationContext(t *testing.T) {
@@ -69,3 +72,39 @@ func TestContextImmutable(t *testing.T) {
 		t.Error("New context automation name is incorrect")
 	}
 }
+
+func TestGetStateChangeFromContext(t *testing.T) {
+	if _, ok := GetStateChangeFromContext(context.Background()); ok {
+		t.Error("Expected no state change in empty context")
+	}
+
+	change := StateChange{EntityID: "light.kitchen"}
+	ctx := WithStateChange(NewAutomationContext("light.kitchen", "test_automation"), change)
+
+	extracted, ok := GetStateChangeFromContext(ctx)
+	if !ok || extracted.EntityID != "light.kitchen" {
+		t.Errorf("Expected state change for light.kitchen, got %+v", extracted)
+	}
+}
+
+func TestStateChange_ChangedAttributes(t *testing.T) {
+	change := StateChange{
+		OldState: homeassistant.State{
+			State:      "on",
+			Attributes: map[string]any{"brightness": float64(100), "color_mode": "rgb", "effect": "none"},
+		},
+		NewState: homeassistant.State{
+			State:      "on",
+			Attributes: map[string]any{"brightness": float64(200), "color_mode": "rgb", "rgb_color": []any{255, 0, 0}},
+		},
+	}
+
+	if change.StateChanged() {
+		t.Error("Expected state to be unchanged")
+	}
+
+	changed := change.ChangedAttributes()
+	if !slices.Equal(changed, []string{"brightness", "effect", "rgb_color"}) {
+		t.Errorf("Expected changed attributes [brightness effect rgb_color], got %v", changed)
+	}
+}
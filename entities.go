@@ -1,9 +1,12 @@
 package hal
 
 import (
+	"encoding/json"
 	"reflect"
 	"sync"
+	"time"
 
+	"github.com/dansimau/hal/hassws"
 	"github.com/dansimau/hal/homeassistant"
 )
 
@@ -60,6 +63,122 @@ func (e *Entity) GetState() homeassistant.State {
 	return e.state
 }
 
+// IsAvailable returns false if Home Assistant reports the entity as
+// unavailable or its state as unknown, or if no state has been received yet.
+func (e *Entity) IsAvailable() bool {
+	return isAvailableState(e.GetState().State)
+}
+
+// LastChanged returns when the state last changed. Attribute-only updates do
+// not affect it.
+func (e *Entity) LastChanged() time.Time {
+	return e.GetState().LastChanged
+}
+
+// LastUpdated returns when the state or any attribute was last updated.
+func (e *Entity) LastUpdated() time.Time {
+	return e.GetState().LastUpdated
+}
+
+// Age returns the time since the entity was last updated, by the connection's
+// clock, which can be used to detect sensors that have stopped reporting. It
+// returns 0 if the entity has never been updated.
+func (e *Entity) Age() time.Duration {
+	lastUpdated := e.LastUpdated()
+	if lastUpdated.IsZero() {
+		return 0
+	}
+
+	return e.now().Sub(lastUpdated)
+}
+
+// now returns the current time from the connection's clock, or the system
+// clock if the entity is not registered.
+func (e *Entity) now() time.Time {
+	if e.connection == nil {
+		return time.Now()
+	}
+
+	return e.connection.getClock().Now()
+}
+
+// location returns the time zone from Config.TimeZone, or the local time zone
+// if the entity is not registered.
+func (e *Entity) location() *time.Location {
+	if e.connection == nil {
+		return time.Local
+	}
+
+	return e.connection.location
+}
+
+func isAvailableState(state string) bool {
+	switch state {
+	case "", homeassistant.StateUnavailable, homeassistant.StateUnknown:
+		return false
+	default:
+		return true
+	}
+}
+
+// supportsFeature returns true if the entity advertises the given feature flag
+// in its supported_features attribute.
+func (e *Entity) supportsFeature(feature int) bool {
+	features, ok := getFloat(e.GetState().Attributes["supported_features"])
+	if !ok {
+		return false
+	}
+
+	return int(features)&feature != 0
+}
+
+// callService calls a service in the given domain targeting this entity. Any
+// extra service data is sent alongside the entity ID.
+func (e *Entity) callService(domain, service string, data map[string]any) error {
+	_, err := e.sendServiceCall(hassws.CallServiceRequest{
+		Domain:  domain,
+		Service: service,
+		Data:    data,
+	})
+
+	return err
+}
+
+// callServiceWithResponse is like callService, but for services that return a
+// response (e.g. weather.get_forecasts), which is returned as raw JSON.
+func (e *Entity) callServiceWithResponse(domain, service string, data map[string]any) (json.RawMessage, error) {
+	resp, err := e.sendServiceCall(hassws.CallServiceRequest{
+		Domain:         domain,
+		Service:        service,
+		Data:           data,
+		ReturnResponse: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Result.Response, nil
+}
+
+func (e *Entity) sendServiceCall(msg hassws.CallServiceRequest) (hassws.CallServiceResponse, error) {
+	if e.connection == nil {
+		return hassws.CallServiceResponse{}, ErrEntityNotRegistered
+	}
+
+	serviceData := map[string]any{
+		"entity_id": []string{e.GetID()},
+	}
+
+	for k, v := range msg.Data {
+		serviceData[k] = v
+	}
+
+	msg.Type = hassws.MessageTypeCallService
+	msg.Data = serviceData
+
+	return e.connection.CallService(msg)
+}
+
 // findEntities recursively finds all entities in a struct, map, or slice.
 func findEntities(v any) []EntityInterface {
 	var entities []EntityInterface
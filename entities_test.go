@@ -0,0 +1,83 @@
+package hal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"gotest.tools/v3/assert"
+)
+
+func TestEntity_IsAvailable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		state string
+		want  bool
+	}{
+		{state: "on", want: true},
+		{state: "off", want: true},
+		{state: "unavailable", want: false},
+		{state: "unknown", want: false},
+		{state: "", want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.state, func(t *testing.T) {
+			t.Parallel()
+			entity := hal.NewEntity("test.entity")
+			entity.SetState(homeassistant.State{EntityID: "test.entity", State: tc.state})
+
+			assert.Equal(t, entity.IsAvailable(), tc.want)
+		})
+	}
+}
+
+func TestEntity_Timestamps(t *testing.T) {
+	t.Parallel()
+
+	entity := hal.NewEntity("test.entity")
+	assert.Equal(t, entity.Age(), time.Duration(0))
+
+	lastChanged := time.Now().Add(-time.Hour)
+	lastUpdated := time.Now().Add(-time.Minute)
+
+	entity.SetState(homeassistant.State{
+		EntityID:    "test.entity",
+		State:       "on",
+		LastChanged: lastChanged,
+		LastUpdated: lastUpdated,
+	})
+
+	assert.Equal(t, entity.LastChanged(), lastChanged)
+	assert.Equal(t, entity.LastUpdated(), lastUpdated)
+	assert.Assert(t, entity.Age() >= time.Minute)
+	assert.Assert(t, entity.Age() < time.Hour)
+}
+
+func TestEntity_AgeUsesConnectionClock(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC))
+	conn.WithClock(mockClock)
+
+	entity := hal.NewEntity("sensor.temperature")
+	conn.RegisterEntities(entity)
+
+	entity.SetState(homeassistant.State{
+		EntityID:    "sensor.temperature",
+		State:       "21.5",
+		LastUpdated: mockClock.Now(),
+	})
+
+	mockClock.Add(90 * time.Minute)
+
+	assert.Equal(t, entity.Age(), 90*time.Minute)
+}
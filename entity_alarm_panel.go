@@ -0,0 +1,212 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+)
+
+// Alarm panel feature flags, as advertised in the supported_features
+// attribute.
+const (
+	AlarmPanelFeatureArmHome = 1 << iota
+	AlarmPanelFeatureArmAway
+	AlarmPanelFeatureArmNight
+	AlarmPanelFeatureTrigger
+	AlarmPanelFeatureArmCustomBypass
+	AlarmPanelFeatureArmVacation
+)
+
+// AlarmState is the state of an alarm control panel.
+type AlarmState string
+
+const (
+	AlarmStateDisarmed          AlarmState = "disarmed"
+	AlarmStateArmedHome         AlarmState = "armed_home"
+	AlarmStateArmedAway         AlarmState = "armed_away"
+	AlarmStateArmedNight        AlarmState = "armed_night"
+	AlarmStateArmedVacation     AlarmState = "armed_vacation"
+	AlarmStateArmedCustomBypass AlarmState = "armed_custom_bypass"
+	AlarmStateArming            AlarmState = "arming"
+	AlarmStateDisarming         AlarmState = "disarming"
+	AlarmStatePending           AlarmState = "pending"
+	AlarmStateTriggered         AlarmState = "triggered"
+)
+
+// IsArmed returns true for any of the armed_* states.
+func (s AlarmState) IsArmed() bool {
+	return strings.HasPrefix(string(s), "armed_")
+}
+
+// AlarmPanel is an alarm control panel. Besides the current state it keeps
+// track of when each state was last entered, so that automations can ask
+// questions like "was the alarm triggered while we were out?".
+type AlarmPanel struct {
+	*Entity
+
+	// historyMutex guards previous and entered.
+	historyMutex sync.RWMutex
+	previous     AlarmState
+	entered      map[AlarmState]time.Time
+}
+
+func NewAlarmPanel(id string) *AlarmPanel {
+	return &AlarmPanel{
+		Entity:  NewEntity(id),
+		entered: make(map[AlarmState]time.Time),
+	}
+}
+
+// SetState updates the state of the panel and records the transition.
+func (a *AlarmPanel) SetState(state homeassistant.State) {
+	current := a.State()
+
+	a.Entity.SetState(state)
+
+	next := AlarmState(state.State)
+	if next == current {
+		return
+	}
+
+	enteredAt := state.LastChanged
+	if enteredAt.IsZero() {
+		enteredAt = a.now()
+	}
+
+	a.historyMutex.Lock()
+	defer a.historyMutex.Unlock()
+
+	if current != "" {
+		a.previous = current
+	}
+
+	a.entered[next] = enteredAt
+}
+
+func (a *AlarmPanel) State() AlarmState {
+	return AlarmState(a.GetState().State)
+}
+
+// PreviousState returns the state the panel was in before the current one, or
+// an empty state if no transition has been seen.
+func (a *AlarmPanel) PreviousState() AlarmState {
+	a.historyMutex.RLock()
+	defer a.historyMutex.RUnlock()
+
+	return a.previous
+}
+
+// LastEntered returns when the panel last entered the given state. The second
+// return value is false if it has not been seen in that state since HAL
+// started.
+func (a *AlarmPanel) LastEntered(state AlarmState) (time.Time, bool) {
+	a.historyMutex.RLock()
+	defer a.historyMutex.RUnlock()
+
+	t, ok := a.entered[state]
+
+	return t, ok
+}
+
+// WasTriggeredSince returns true if the alarm was triggered at or after t.
+func (a *AlarmPanel) WasTriggeredSince(t time.Time) bool {
+	triggeredAt, ok := a.LastEntered(AlarmStateTriggered)
+
+	return ok && !triggeredAt.Before(t)
+}
+
+func (a *AlarmPanel) IsDisarmed() bool {
+	return a.State() == AlarmStateDisarmed
+}
+
+// IsArmed returns true if the panel is in any of the armed_* states.
+func (a *AlarmPanel) IsArmed() bool {
+	return a.State().IsArmed()
+}
+
+func (a *AlarmPanel) IsArming() bool {
+	return a.State() == AlarmStateArming
+}
+
+func (a *AlarmPanel) IsPending() bool {
+	return a.State() == AlarmStatePending
+}
+
+func (a *AlarmPanel) IsTriggered() bool {
+	return a.State() == AlarmStateTriggered
+}
+
+// CodeArmRequired returns true if a code must be given to arm the panel.
+func (a *AlarmPanel) CodeArmRequired() bool {
+	required, _ := a.GetState().Attributes["code_arm_required"].(bool)
+
+	return required
+}
+
+// ArmHome arms the panel in home mode. The code may be empty for panels that
+// do not require one.
+func (a *AlarmPanel) ArmHome(ctx context.Context, code string) error {
+	logger.InfoContext(ctx, "Arming alarm (home)", "alarm_control_panel", a.GetID())
+
+	return a.call(ctx, AlarmPanelFeatureArmHome, "alarm_arm_home", code)
+}
+
+func (a *AlarmPanel) ArmAway(ctx context.Context, code string) error {
+	logger.InfoContext(ctx, "Arming alarm (away)", "alarm_control_panel", a.GetID())
+
+	return a.call(ctx, AlarmPanelFeatureArmAway, "alarm_arm_away", code)
+}
+
+func (a *AlarmPanel) ArmNight(ctx context.Context, code string) error {
+	logger.InfoContext(ctx, "Arming alarm (night)", "alarm_control_panel", a.GetID())
+
+	return a.call(ctx, AlarmPanelFeatureArmNight, "alarm_arm_night", code)
+}
+
+func (a *AlarmPanel) ArmVacation(ctx context.Context, code string) error {
+	logger.InfoContext(ctx, "Arming alarm (vacation)", "alarm_control_panel", a.GetID())
+
+	return a.call(ctx, AlarmPanelFeatureArmVacation, "alarm_arm_vacation", code)
+}
+
+func (a *AlarmPanel) Disarm(ctx context.Context, code string) error {
+	logger.InfoContext(ctx, "Disarming alarm", "alarm_control_panel", a.GetID())
+
+	return a.call(ctx, 0, "alarm_disarm", code)
+}
+
+// Trigger sets off the alarm.
+func (a *AlarmPanel) Trigger(ctx context.Context, code string) error {
+	logger.InfoContext(ctx, "Triggering alarm", "alarm_control_panel", a.GetID())
+
+	return a.call(ctx, AlarmPanelFeatureTrigger, "alarm_trigger", code)
+}
+
+// call calls an alarm panel service if the panel supports the feature it
+// requires. A feature of 0 means the service is always supported.
+func (a *AlarmPanel) call(ctx context.Context, feature int, service string, code string) error {
+	if feature != 0 && !a.supportsFeature(feature) {
+		err := fmt.Errorf("%w: alarm_control_panel.%s", ErrFeatureNotSupported, service)
+		logger.ErrorContext(ctx, "Alarm panel does not support service", "alarm_control_panel", a.GetID(), "error", err)
+
+		return err
+	}
+
+	var data map[string]any
+	if code != "" {
+		data = map[string]any{"code": code}
+	}
+
+	if err := a.callService("alarm_control_panel", service, data); err != nil {
+		logger.ErrorContext(ctx, "Error calling alarm panel service", "alarm_control_panel", a.GetID(), "service", service, "error", err)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestAlarmPanel_Transitions(t *testing.T) {
+	t.Parallel()
+
+	alarm := hal.NewAlarmPanel("alarm_control_panel.home")
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	setState := func(state hal.AlarmState, at time.Time) {
+		alarm.SetState(homeassistant.State{
+			EntityID:    alarm.GetID(),
+			State:       string(state),
+			LastChanged: at,
+		})
+	}
+
+	setState(hal.AlarmStateArmedAway, start)
+	assert.Assert(t, alarm.IsArmed())
+	assert.Equal(t, alarm.PreviousState(), hal.AlarmState(""))
+	assert.Assert(t, !alarm.WasTriggeredSince(start))
+
+	setState(hal.AlarmStatePending, start.Add(time.Hour))
+	setState(hal.AlarmStateTriggered, start.Add(time.Hour+30*time.Second))
+	assert.Assert(t, alarm.IsTriggered())
+	assert.Equal(t, alarm.PreviousState(), hal.AlarmStatePending)
+
+	setState(hal.AlarmStateDisarmed, start.Add(2*time.Hour))
+	assert.Assert(t, alarm.IsDisarmed())
+	assert.Assert(t, !alarm.IsArmed())
+	assert.Equal(t, alarm.PreviousState(), hal.AlarmStateTriggered)
+
+	assert.Assert(t, alarm.WasTriggeredSince(start))
+	assert.Assert(t, !alarm.WasTriggeredSince(start.Add(2*time.Hour)))
+
+	armedAt, ok := alarm.LastEntered(hal.AlarmStateArmedAway)
+	assert.Assert(t, ok)
+	assert.Equal(t, armedAt, start)
+
+	// Repeated updates with the same state are not transitions.
+	setState(hal.AlarmStateDisarmed, start.Add(3*time.Hour))
+	assert.Equal(t, alarm.PreviousState(), hal.AlarmStateTriggered)
+
+	disarmedAt, _ := alarm.LastEntered(hal.AlarmStateDisarmed)
+	assert.Equal(t, disarmedAt, start.Add(2*time.Hour))
+}
+
+func TestAlarmPanel_TransitionWithoutLastChanged(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC))
+	conn.WithClock(mockClock)
+
+	alarm := hal.NewAlarmPanel("alarm_control_panel.home")
+	conn.RegisterEntities(alarm)
+
+	alarm.SetState(homeassistant.State{EntityID: alarm.GetID(), State: string(hal.AlarmStateTriggered)})
+
+	// The time is taken from the connection's clock.
+	triggeredAt, ok := alarm.LastEntered(hal.AlarmStateTriggered)
+	assert.Assert(t, ok)
+	assert.Equal(t, triggeredAt, mockClock.Now())
+}
+
+func TestAlarmPanel_ServiceCalls(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	alarm := hal.NewAlarmPanel("alarm_control_panel.home")
+	conn.RegisterEntities(alarm)
+
+	ctx := context.Background()
+
+	assert.ErrorIs(t, alarm.ArmAway(ctx, ""), hal.ErrFeatureNotSupported)
+
+	// Seed the server so supported_features survives the generated state changes.
+	state := homeassistant.State{
+		EntityID: alarm.GetID(),
+		State:    string(hal.AlarmStateDisarmed),
+		Attributes: map[string]any{
+			"supported_features": float64(hal.AlarmPanelFeatureArmHome | hal.AlarmPanelFeatureArmAway),
+		},
+	}
+	server.SendEvent(homeassistant.Event{
+		EventType: "state_changed",
+		EventData: homeassistant.EventData{
+			EntityID: alarm.GetID(),
+			NewState: &state,
+		},
+	})
+
+	testutil.WaitFor(t, "verify alarm disarmed", alarm.IsDisarmed, func() {
+		spew.Dump(alarm.GetState())
+	})
+
+	assert.ErrorIs(t, alarm.Trigger(ctx, ""), hal.ErrFeatureNotSupported)
+
+	assert.NilError(t, alarm.ArmAway(ctx, "1234"))
+	testutil.WaitFor(t, "verify alarm armed away", func() bool {
+		return alarm.State() == hal.AlarmStateArmedAway
+	}, func() {
+		spew.Dump(alarm.GetState())
+	})
+
+	assert.NilError(t, alarm.Disarm(ctx, "1234"))
+	testutil.WaitFor(t, "verify alarm disarmed", alarm.IsDisarmed, func() {
+		spew.Dump(alarm.GetState())
+	})
+	assert.Equal(t, alarm.PreviousState(), hal.AlarmStateArmedAway)
+}
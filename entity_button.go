@@ -1,51 +1,237 @@
 package hal
 
 import (
+	"context"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/dansimau/hal/logger"
 )
 
-// buttonPressTimeout is the amount of time to listen for repeat presses.
+// buttonPressTimeout is the default amount of time to listen for repeat
+// presses before a multi-press gesture is considered complete.
 const buttonPressTimeout = 2 * time.Second
 
-// Button is an event entity that represents a button.
+// Button event types, as fired by event entities for buttons and remotes.
+const (
+	ButtonEventInitialPress = "initial_press"
+	ButtonEventRepeat       = "repeat"
+	ButtonEventShortRelease = "short_release"
+	ButtonEventLongPress    = "long_press"
+	ButtonEventLongRelease  = "long_release"
+	ButtonEventDoublePress  = "double_press"
+	ButtonEventTriplePress  = "triple_press"
+)
+
+// Button is an event entity that represents a button. It turns the raw events
+// fired by the button into gestures:
+//
+//   - Presses: each initial_press within the press window is counted, and the
+//     handlers for that number of presses fire once the window closes. Buttons
+//     that detect double_press and triple_press themselves fire immediately.
+//   - Long press: long_press fires the long press handlers once.
+//   - Hold and release: hold handlers fire when the button is held (long_press)
+//     and on each repeat event while it is held; release handlers fire on
+//     long_release.
 type Button struct {
-	*Entity
+	*EventEntity
 
-	lastPressed  time.Time
-	pressedTimes int32
+	pressWindow time.Duration
+	pressTimer  *Timer
+
+	// mutex guards the fields below, which are also accessed by the press
+	// timer goroutine.
+	mutex             sync.Mutex
+	inSequence        bool
+	pressedTimes      int32
+	holding           bool
+	pressHandlers     map[int32][]func(context.Context)
+	longPressHandlers []func(context.Context)
+	holdHandlers      []func(context.Context)
+	releaseHandlers   []func(context.Context)
 }
 
 func NewButton(id string) *Button {
-	return &Button{Entity: NewEntity(id)}
+	return &Button{
+		EventEntity:   NewEventEntity(id),
+		pressWindow:   buttonPressTimeout,
+		pressTimer:    NewTimer(clock.New()),
+		pressHandlers: make(map[int32][]func(context.Context)),
+	}
+}
+
+// WithClock can be used to pass in a mock clock for testing.
+func (b *Button) WithClock(c clock.Clock) *Button {
+	b.pressTimer = NewTimer(c)
+
+	return b
+}
+
+// WithPressWindow sets how long to wait for another press before a
+// multi-press gesture is considered complete.
+func (b *Button) WithPressWindow(window time.Duration) *Button {
+	b.pressWindow = window
+
+	return b
+}
+
+// OnPress registers a handler for when the button is pressed the given number
+// of times in a row, e.g. 2 for a double press.
+func (b *Button) OnPress(times int, handler func(ctx context.Context)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.pressHandlers[int32(times)] = append(b.pressHandlers[int32(times)], handler)
 }
 
-func (b *Button) Name() string {
-	return b.GetID()
+// OnLongPress registers a handler for when the button is held down.
+func (b *Button) OnLongPress(handler func(ctx context.Context)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.longPressHandlers = append(b.longPressHandlers, handler)
+}
+
+// OnHold registers a handler that is called when the button is held down and
+// repeatedly while it remains held, e.g. to dim a light step by step.
+func (b *Button) OnHold(handler func(ctx context.Context)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.holdHandlers = append(b.holdHandlers, handler)
+}
+
+// OnRelease registers a handler for when the button is released after being
+// held.
+func (b *Button) OnRelease(handler func(ctx context.Context)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.releaseHandlers = append(b.releaseHandlers, handler)
+}
+
+// PressedTimes returns the number of presses in the current or most recent
+// multi-press gesture.
+func (b *Button) PressedTimes() int32 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.pressedTimes
+}
+
+// IsHeld returns true if the button is currently held down.
+func (b *Button) IsHeld() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.holding
 }
 
 func (b *Button) Entities() Entities {
 	return Entities{b}
 }
 
-func (b *Button) Action(_ EntityInterface) {
-	if b.Entity.GetState().Attributes["event_type"] != "initial_press" {
+func (b *Button) Action(ctx context.Context, trigger EntityInterface) {
+	b.EventEntity.Action(ctx, trigger)
+
+	event, ok := b.Event()
+	if !ok {
 		return
 	}
 
-	if time.Since(b.lastPressed) < buttonPressTimeout {
+	switch event.EventType {
+	case ButtonEventInitialPress:
+		b.press(ctx)
+	case ButtonEventDoublePress:
+		b.completePresses(ctx, 2)
+	case ButtonEventTriplePress:
+		b.completePresses(ctx, 3)
+	case ButtonEventLongPress:
+		b.longPress(ctx)
+	case ButtonEventRepeat:
+		b.mutex.Lock()
+		holding := b.holding
+		handlers := slices.Clone(b.holdHandlers)
+		b.mutex.Unlock()
+
+		if holding {
+			runButtonHandlers(ctx, handlers)
+		}
+	case ButtonEventLongRelease:
+		b.mutex.Lock()
+		b.holding = false
+		handlers := slices.Clone(b.releaseHandlers)
+		b.mutex.Unlock()
+
+		logger.InfoContext(ctx, "Button released", "button", b.GetID())
+		runButtonHandlers(ctx, handlers)
+	}
+}
+
+// press counts a press and (re)starts the press window.
+func (b *Button) press(ctx context.Context) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.inSequence {
 		b.pressedTimes++
 	} else {
+		b.inSequence = true
 		b.pressedTimes = 1
 	}
 
-	entityID := b.GetID()
-	logger.Info("Button pressed", entityID, "times", b.pressedTimes)
+	b.pressTimer.StartContext(ctx, b.pressWindowClosed, b.pressWindow)
+}
+
+// pressWindowClosed runs when no further press arrived within the press
+// window, and fires the handlers for the number of presses counted.
+func (b *Button) pressWindowClosed(ctx context.Context) {
+	b.mutex.Lock()
+	if !b.inSequence {
+		b.mutex.Unlock()
 
-	b.lastPressed = time.Now()
+		return
+	}
+
+	b.inSequence = false
+	times := b.pressedTimes
+	b.mutex.Unlock()
+
+	b.completePresses(ctx, times)
 }
 
-func (b *Button) PressedTimes() int32 {
-	return b.pressedTimes
+func (b *Button) completePresses(ctx context.Context, times int32) {
+	b.mutex.Lock()
+	b.pressedTimes = times
+	handlers := slices.Clone(b.pressHandlers[times])
+	b.mutex.Unlock()
+
+	logger.InfoContext(ctx, "Button pressed", "button", b.GetID(), "times", times)
+	runButtonHandlers(ctx, handlers)
+}
+
+// longPress ends any multi-press gesture in progress, since the initial press
+// was the start of a long press, and fires the long press and hold handlers.
+func (b *Button) longPress(ctx context.Context) {
+	b.mutex.Lock()
+	b.inSequence = false
+	b.pressedTimes = 0
+	b.holding = true
+	longPressHandlers := slices.Clone(b.longPressHandlers)
+	holdHandlers := slices.Clone(b.holdHandlers)
+	b.mutex.Unlock()
+
+	b.pressTimer.Cancel()
+
+	logger.InfoContext(ctx, "Button long pressed", "button", b.GetID())
+	runButtonHandlers(ctx, longPressHandlers)
+	runButtonHandlers(ctx, holdHandlers)
+}
+
+func runButtonHandlers(ctx context.Context, handlers []func(context.Context)) {
+	for _, handler := range handlers {
+		handler(ctx)
+	}
 }
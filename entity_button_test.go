@@ -0,0 +1,189 @@
+package hal_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+// buttonGestures records the gestures fired by a button.
+type buttonGestures struct {
+	mutex     sync.Mutex
+	presses   []int
+	longPress int
+	hold      int
+	release   int
+}
+
+func (g *buttonGestures) snapshot() (presses []int, longPress, hold, release int) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return append([]int(nil), g.presses...), g.longPress, g.hold, g.release
+}
+
+func newTestButton(t *testing.T) (*hal.Button, *clock.Mock, *buttonGestures, func(eventType string)) {
+	t.Helper()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	t.Cleanup(cleanup)
+
+	mockClock := clock.NewMock()
+	button := hal.NewButton("event.hallway_button").WithClock(mockClock).WithPressWindow(time.Second)
+	gestures := &buttonGestures{}
+
+	for _, times := range []int{1, 2, 3} {
+		button.OnPress(times, func(context.Context) {
+			gestures.mutex.Lock()
+			defer gestures.mutex.Unlock()
+
+			gestures.presses = append(gestures.presses, times)
+		})
+	}
+
+	button.OnLongPress(func(context.Context) {
+		gestures.mutex.Lock()
+		defer gestures.mutex.Unlock()
+
+		gestures.longPress++
+	})
+
+	button.OnHold(func(context.Context) {
+		gestures.mutex.Lock()
+		defer gestures.mutex.Unlock()
+
+		gestures.hold++
+	})
+
+	button.OnRelease(func(context.Context) {
+		gestures.mutex.Lock()
+		defer gestures.mutex.Unlock()
+
+		gestures.release++
+	})
+
+	conn.RegisterEntities(button)
+
+	firedAt := time.Now()
+
+	sendEvent := func(eventType string) {
+		firedAt = firedAt.Add(100 * time.Millisecond)
+
+		server.SendEvent(homeassistant.Event{
+			EventType: "state_changed",
+			EventData: homeassistant.EventData{
+				EntityID: button.GetID(),
+				NewState: &homeassistant.State{
+					EntityID:   button.GetID(),
+					State:      firedAt.Format(time.RFC3339Nano),
+					Attributes: map[string]any{"event_type": eventType},
+				},
+			},
+		})
+	}
+
+	return button, mockClock, gestures, sendEvent
+}
+
+func TestButton_IsAutomation(t *testing.T) {
+	t.Parallel()
+
+	// Buttons must implement Automation so that RegisterEntities also
+	// registers them for dispatch.
+	var automation hal.Automation = hal.NewButton("event.hallway_button")
+
+	assert.Equal(t, automation.Name(), "event.hallway_button")
+	assert.Equal(t, len(automation.Entities()), 1)
+}
+
+func TestButton_MultiPress(t *testing.T) {
+	t.Parallel()
+
+	button, mockClock, gestures, sendEvent := newTestButton(t)
+
+	sendEvent(hal.ButtonEventInitialPress)
+	sendEvent(hal.ButtonEventShortRelease)
+	sendEvent(hal.ButtonEventInitialPress)
+	sendEvent(hal.ButtonEventShortRelease)
+
+	testutil.WaitFor(t, "verify presses counted", func() bool {
+		return button.PressedTimes() == 2
+	}, func() {
+		spew.Dump(button.GetState())
+	})
+
+	// Nothing fires until the press window closes.
+	presses, _, _, _ := gestures.snapshot()
+	assert.Equal(t, len(presses), 0)
+
+	mockClock.Add(time.Second)
+
+	testutil.WaitFor(t, "verify double press fired", func() bool {
+		presses, _, _, _ := gestures.snapshot()
+
+		return len(presses) == 1
+	}, func() {
+		spew.Dump(gestures.snapshot())
+	})
+
+	presses, _, _, _ = gestures.snapshot()
+	assert.DeepEqual(t, presses, []int{2})
+
+	// Buttons that detect multi-presses themselves fire immediately.
+	sendEvent(hal.ButtonEventTriplePress)
+
+	testutil.WaitFor(t, "verify triple press fired", func() bool {
+		presses, _, _, _ := gestures.snapshot()
+
+		return len(presses) == 2
+	}, func() {
+		spew.Dump(gestures.snapshot())
+	})
+
+	presses, _, _, _ = gestures.snapshot()
+	assert.DeepEqual(t, presses, []int{2, 3})
+}
+
+func TestButton_LongPress(t *testing.T) {
+	t.Parallel()
+
+	button, mockClock, gestures, sendEvent := newTestButton(t)
+
+	sendEvent(hal.ButtonEventInitialPress)
+	sendEvent(hal.ButtonEventLongPress)
+	sendEvent(hal.ButtonEventRepeat)
+	sendEvent(hal.ButtonEventRepeat)
+
+	testutil.WaitFor(t, "verify button held", func() bool {
+		_, _, hold, _ := gestures.snapshot()
+
+		return button.IsHeld() && hold == 3
+	}, func() {
+		spew.Dump(gestures.snapshot())
+	})
+
+	sendEvent(hal.ButtonEventLongRelease)
+
+	testutil.WaitFor(t, "verify button released", func() bool {
+		_, _, _, release := gestures.snapshot()
+
+		return !button.IsHeld() && release == 1
+	}, func() {
+		spew.Dump(gestures.snapshot())
+	})
+
+	// The initial press was part of the long press, so no single press fires.
+	mockClock.Add(time.Second)
+
+	presses, longPress, _, _ := gestures.snapshot()
+	assert.Equal(t, len(presses), 0)
+	assert.Equal(t, longPress, 1)
+}
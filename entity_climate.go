@@ -0,0 +1,196 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// HVAC modes as reported in the state of a climate entity.
+const (
+	HVACModeOff      = "off"
+	HVACModeHeat     = "heat"
+	HVACModeCool     = "cool"
+	HVACModeHeatCool = "heat_cool"
+	HVACModeAuto     = "auto"
+	HVACModeDry      = "dry"
+	HVACModeFanOnly  = "fan_only"
+)
+
+// Climate is a thermostat or other HVAC device.
+type Climate struct {
+	*Entity
+}
+
+func NewClimate(id string) *Climate {
+	return &Climate{Entity: NewEntity(id)}
+}
+
+// HVACMode returns the current HVAC mode, which is the state of the entity.
+func (c *Climate) HVACMode() string {
+	return c.GetState().State
+}
+
+// HVACModes returns the HVAC modes supported by the entity.
+func (c *Climate) HVACModes() []string {
+	return getStringOrStringSlice(c.GetState().Attributes["hvac_modes"])
+}
+
+// HVACAction returns what the device is currently doing, e.g. "heating" or
+// "idle".
+func (c *Climate) HVACAction() string {
+	return getString(c.GetState().Attributes["hvac_action"])
+}
+
+// CurrentTemperature returns the temperature measured by the device. The
+// second return value is false if the device does not report one.
+func (c *Climate) CurrentTemperature() (float64, bool) {
+	return getFloat(c.GetState().Attributes["current_temperature"])
+}
+
+// TargetTemperature returns the temperature the device is trying to reach.
+// The second return value is false if the device is off or uses a range.
+func (c *Climate) TargetTemperature() (float64, bool) {
+	return getFloat(c.GetState().Attributes["temperature"])
+}
+
+// TargetTemperatureLow returns the lower bound of the target range.
+func (c *Climate) TargetTemperatureLow() (float64, bool) {
+	return getFloat(c.GetState().Attributes["target_temp_low"])
+}
+
+// TargetTemperatureHigh returns the upper bound of the target range.
+func (c *Climate) TargetTemperatureHigh() (float64, bool) {
+	return getFloat(c.GetState().Attributes["target_temp_high"])
+}
+
+// MinTemp returns the lowest target temperature the device accepts.
+func (c *Climate) MinTemp() (float64, bool) {
+	return getFloat(c.GetState().Attributes["min_temp"])
+}
+
+// MaxTemp returns the highest target temperature the device accepts.
+func (c *Climate) MaxTemp() (float64, bool) {
+	return getFloat(c.GetState().Attributes["max_temp"])
+}
+
+func (c *Climate) PresetMode() string {
+	return getString(c.GetState().Attributes["preset_mode"])
+}
+
+func (c *Climate) PresetModes() []string {
+	return getStringOrStringSlice(c.GetState().Attributes["preset_modes"])
+}
+
+func (c *Climate) FanMode() string {
+	return getString(c.GetState().Attributes["fan_mode"])
+}
+
+func (c *Climate) FanModes() []string {
+	return getStringOrStringSlice(c.GetState().Attributes["fan_modes"])
+}
+
+// SetTemperature sets the target temperature.
+func (c *Climate) SetTemperature(ctx context.Context, temperature float64) error {
+	if err := c.validateTemperature(temperature); err != nil {
+		logger.ErrorContext(ctx, "Invalid target temperature", "climate", c.GetID(), "error", err)
+
+		return err
+	}
+
+	logger.InfoContext(ctx, "Setting target temperature", "climate", c.GetID(), "temperature", temperature)
+
+	return c.setTemperature(ctx, map[string]any{"temperature": temperature})
+}
+
+// SetTemperatureRange sets the target temperature range, for devices in
+// heat_cool mode.
+func (c *Climate) SetTemperatureRange(ctx context.Context, low, high float64) error {
+	err := c.validateTemperature(low)
+	if err == nil {
+		err = c.validateTemperature(high)
+	}
+
+	if err == nil && low > high {
+		err = fmt.Errorf("%w: low %v is above high %v", ErrValueOutOfRange, low, high)
+	}
+
+	if err != nil {
+		logger.ErrorContext(ctx, "Invalid target temperature range", "climate", c.GetID(), "error", err)
+
+		return err
+	}
+
+	logger.InfoContext(ctx, "Setting target temperature range", "climate", c.GetID(), "low", low, "high", high)
+
+	return c.setTemperature(ctx, map[string]any{
+		"target_temp_low":  low,
+		"target_temp_high": high,
+	})
+}
+
+func (c *Climate) setTemperature(ctx context.Context, data map[string]any) error {
+	if err := c.callService("climate", "set_temperature", data); err != nil {
+		logger.ErrorContext(ctx, "Error setting temperature", "climate", c.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// SetHVACMode sets the HVAC mode. The mode must be one of HVACModes.
+func (c *Climate) SetHVACMode(ctx context.Context, mode string) error {
+	if !slices.Contains(c.HVACModes(), mode) {
+		err := fmt.Errorf("%w: hvac mode %q not in %v", ErrUnsupportedValue, mode, c.HVACModes())
+		logger.ErrorContext(ctx, "Invalid HVAC mode", "climate", c.GetID(), "error", err)
+
+		return err
+	}
+
+	logger.InfoContext(ctx, "Setting HVAC mode", "climate", c.GetID(), "mode", mode)
+
+	if err := c.callService("climate", "set_hvac_mode", map[string]any{"hvac_mode": mode}); err != nil {
+		logger.ErrorContext(ctx, "Error setting HVAC mode", "climate", c.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// SetPresetMode sets the preset mode. The preset must be one of PresetModes.
+func (c *Climate) SetPresetMode(ctx context.Context, preset string) error {
+	if !slices.Contains(c.PresetModes(), preset) {
+		err := fmt.Errorf("%w: preset mode %q not in %v", ErrUnsupportedValue, preset, c.PresetModes())
+		logger.ErrorContext(ctx, "Invalid preset mode", "climate", c.GetID(), "error", err)
+
+		return err
+	}
+
+	logger.InfoContext(ctx, "Setting preset mode", "climate", c.GetID(), "preset", preset)
+
+	if err := c.callService("climate", "set_preset_mode", map[string]any{"preset_mode": preset}); err != nil {
+		logger.ErrorContext(ctx, "Error setting preset mode", "climate", c.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// validateTemperature checks the temperature against the min_temp and max_temp
+// attributes advertised by the entity.
+func (c *Climate) validateTemperature(temperature float64) error {
+	if minTemp, ok := c.MinTemp(); ok && temperature < minTemp {
+		return fmt.Errorf("%w: %v is below min_temp %v", ErrValueOutOfRange, temperature, minTemp)
+	}
+
+	if maxTemp, ok := c.MaxTemp(); ok && temperature > maxTemp {
+		return fmt.Errorf("%w: %v is above max_temp %v", ErrValueOutOfRange, temperature, maxTemp)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func newTestClimate() *hal.Climate {
+	climate := hal.NewClimate("climate.test")
+	climate.SetState(homeassistant.State{
+		EntityID: "climate.test",
+		State:    "heat",
+		Attributes: map[string]any{
+			"hvac_modes":          []any{"off", "heat", "auto"},
+			"hvac_action":         "heating",
+			"current_temperature": float64(19.5),
+			"temperature":         float64(21),
+			"min_temp":            float64(7),
+			"max_temp":            float64(30),
+			"preset_modes":        []any{"home", "away", "eco"},
+			"preset_mode":         "home",
+		},
+	})
+
+	return climate
+}
+
+func TestClimate_Attributes(t *testing.T) {
+	t.Parallel()
+
+	climate := newTestClimate()
+
+	assert.Equal(t, climate.HVACMode(), hal.HVACModeHeat)
+	assert.DeepEqual(t, climate.HVACModes(), []string{"off", "heat", "auto"})
+	assert.Equal(t, climate.HVACAction(), "heating")
+	assert.Equal(t, climate.PresetMode(), "home")
+
+	current, ok := climate.CurrentTemperature()
+	assert.Assert(t, ok)
+	assert.Equal(t, current, 19.5)
+
+	target, ok := climate.TargetTemperature()
+	assert.Assert(t, ok)
+	assert.Equal(t, target, float64(21))
+
+	_, ok = climate.TargetTemperatureLow()
+	assert.Assert(t, !ok)
+}
+
+func TestClimate_Validation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	climate := newTestClimate()
+
+	assert.ErrorIs(t, climate.SetTemperature(ctx, 35), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, climate.SetTemperature(ctx, 5), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, climate.SetTemperatureRange(ctx, 22, 18), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, climate.SetHVACMode(ctx, hal.HVACModeCool), hal.ErrUnsupportedValue)
+	assert.ErrorIs(t, climate.SetPresetMode(ctx, "boost"), hal.ErrUnsupportedValue)
+
+	// Valid values get as far as the connection.
+	assert.ErrorIs(t, climate.SetTemperature(ctx, 22), hal.ErrEntityNotRegistered)
+	assert.ErrorIs(t, climate.SetHVACMode(ctx, hal.HVACModeAuto), hal.ErrEntityNotRegistered)
+}
+
+func TestClimate_ServiceCalls(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	climate := newTestClimate()
+	conn.RegisterEntities(climate)
+
+	// Seed the server with the entity's state so its attributes survive the
+	// state changes generated by the service calls below.
+	state := climate.GetState()
+	server.SendEvent(homeassistant.Event{
+		EventType: "state_changed",
+		EventData: homeassistant.EventData{
+			EntityID: climate.GetID(),
+			NewState: &state,
+		},
+	})
+
+	ctx := context.Background()
+
+	assert.NilError(t, climate.SetHVACMode(ctx, hal.HVACModeOff))
+	testutil.WaitFor(t, "verify hvac mode changed", func() bool {
+		return climate.HVACMode() == hal.HVACModeOff
+	}, func() {
+		spew.Dump(climate.GetState())
+	})
+
+	assert.NilError(t, climate.SetTemperature(ctx, 23))
+	testutil.WaitFor(t, "verify target temperature changed", func() bool {
+		target, _ := climate.TargetTemperature()
+
+		return target == 23
+	}, func() {
+		spew.Dump(climate.GetState())
+	})
+}
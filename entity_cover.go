@@ -0,0 +1,214 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+)
+
+// Cover feature flags, as advertised in the supported_features attribute.
+const (
+	CoverFeatureOpen = 1 << iota
+	CoverFeatureClose
+	CoverFeatureSetPosition
+	CoverFeatureStop
+	CoverFeatureOpenTilt
+	CoverFeatureCloseTilt
+	CoverFeatureStopTilt
+	CoverFeatureSetTiltPosition
+)
+
+type CoverInterface interface {
+	EntityInterface
+
+	IsOpen() bool
+	IsClosed() bool
+	Open(ctx context.Context) error
+	Close(ctx context.Context) error
+	Stop(ctx context.Context) error
+	SetPosition(ctx context.Context, position int) error
+}
+
+// Cover is a blind, shutter, garage door or similar.
+type Cover struct {
+	*Entity
+}
+
+func NewCover(id string) *Cover {
+	return &Cover{Entity: NewEntity(id)}
+}
+
+// Position returns the current position, where 0 is closed and 100 is fully
+// open. The second return value is false if the cover does not report one.
+func (c *Cover) Position() (int, bool) {
+	position, ok := getFloat(c.GetState().Attributes["current_position"])
+
+	return int(position), ok
+}
+
+// TiltPosition returns the current tilt position, where 0 is closed and 100
+// is fully open. The second return value is false if the cover does not tilt.
+func (c *Cover) TiltPosition() (int, bool) {
+	position, ok := getFloat(c.GetState().Attributes["current_tilt_position"])
+
+	return int(position), ok
+}
+
+func (c *Cover) IsOpen() bool {
+	return c.GetState().State == "open"
+}
+
+func (c *Cover) IsClosed() bool {
+	return c.GetState().State == "closed"
+}
+
+func (c *Cover) IsOpening() bool {
+	return c.GetState().State == "opening"
+}
+
+func (c *Cover) IsClosing() bool {
+	return c.GetState().State == "closing"
+}
+
+func (c *Cover) Open(ctx context.Context) error {
+	logger.InfoContext(ctx, "Opening cover", "cover", c.GetID())
+
+	return c.call(ctx, CoverFeatureOpen, "open_cover", nil)
+}
+
+func (c *Cover) Close(ctx context.Context) error {
+	logger.InfoContext(ctx, "Closing cover", "cover", c.GetID())
+
+	return c.call(ctx, CoverFeatureClose, "close_cover", nil)
+}
+
+func (c *Cover) Stop(ctx context.Context) error {
+	logger.InfoContext(ctx, "Stopping cover", "cover", c.GetID())
+
+	return c.call(ctx, CoverFeatureStop, "stop_cover", nil)
+}
+
+// SetPosition moves the cover to the given position (0-100).
+func (c *Cover) SetPosition(ctx context.Context, position int) error {
+	if position < 0 || position > 100 {
+		return fmt.Errorf("%w: cover position %d", ErrValueOutOfRange, position)
+	}
+
+	logger.InfoContext(ctx, "Setting cover position", "cover", c.GetID(), "position", position)
+
+	return c.call(ctx, CoverFeatureSetPosition, "set_cover_position", map[string]any{"position": position})
+}
+
+// SetTiltPosition tilts the cover to the given position (0-100).
+func (c *Cover) SetTiltPosition(ctx context.Context, position int) error {
+	if position < 0 || position > 100 {
+		return fmt.Errorf("%w: cover tilt position %d", ErrValueOutOfRange, position)
+	}
+
+	logger.InfoContext(ctx, "Setting cover tilt position", "cover", c.GetID(), "position", position)
+
+	return c.call(ctx, CoverFeatureSetTiltPosition, "set_cover_tilt_position", map[string]any{"tilt_position": position})
+}
+
+// call calls a cover service if the cover supports the feature it requires.
+func (c *Cover) call(ctx context.Context, feature int, service string, data map[string]any) error {
+	if !c.supportsFeature(feature) {
+		err := fmt.Errorf("%w: cover.%s", ErrFeatureNotSupported, service)
+		logger.ErrorContext(ctx, "Cover does not support service", "cover", c.GetID(), "error", err)
+
+		return err
+	}
+
+	if err := c.callService("cover", service, data); err != nil {
+		logger.ErrorContext(ctx, "Error calling cover service", "cover", c.GetID(), "service", service, "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// CoverGroup treats a set of covers as one.
+type CoverGroup []CoverInterface
+
+func (cg CoverGroup) BindConnection(connection *Connection) {
+	for _, c := range cg {
+		c.BindConnection(connection)
+	}
+}
+
+func (cg CoverGroup) GetID() string {
+	if len(cg) == 0 {
+		return "(empty cover group)"
+	}
+
+	ids := make([]string, len(cg))
+	for i, c := range cg {
+		ids[i] = c.GetID()
+	}
+
+	return strings.Join(ids, ", ")
+}
+
+func (cg CoverGroup) GetState() homeassistant.State {
+	if len(cg) == 0 {
+		return homeassistant.State{}
+	}
+
+	return cg[0].GetState()
+}
+
+func (cg CoverGroup) SetState(state homeassistant.State) {
+	for _, c := range cg {
+		c.SetState(state)
+	}
+}
+
+// IsOpen returns true if all covers in the group are open.
+func (cg CoverGroup) IsOpen() bool {
+	for _, c := range cg {
+		if !c.IsOpen() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsClosed returns true if all covers in the group are closed.
+func (cg CoverGroup) IsClosed() bool {
+	for _, c := range cg {
+		if !c.IsClosed() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (cg CoverGroup) Open(ctx context.Context) error {
+	return eachMember(cg, func(c CoverInterface) error {
+		return c.Open(ctx)
+	})
+}
+
+func (cg CoverGroup) Close(ctx context.Context) error {
+	return eachMember(cg, func(c CoverInterface) error {
+		return c.Close(ctx)
+	})
+}
+
+func (cg CoverGroup) Stop(ctx context.Context) error {
+	return eachMember(cg, func(c CoverInterface) error {
+		return c.Stop(ctx)
+	})
+}
+
+func (cg CoverGroup) SetPosition(ctx context.Context, position int) error {
+	return eachMember(cg, func(c CoverInterface) error {
+		return c.SetPosition(ctx, position)
+	})
+}
@@ -0,0 +1,126 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func newTestCover(id string, features int) *hal.Cover {
+	cover := hal.NewCover(id)
+	cover.SetState(homeassistant.State{
+		EntityID: id,
+		State:    "closed",
+		Attributes: map[string]any{
+			"current_position":   float64(0),
+			"supported_features": float64(features),
+		},
+	})
+
+	return cover
+}
+
+func TestCover_State(t *testing.T) {
+	t.Parallel()
+
+	cover := hal.NewCover("cover.test")
+	cover.SetState(homeassistant.State{
+		State: "opening",
+		Attributes: map[string]any{
+			"current_position":      float64(40),
+			"current_tilt_position": float64(20),
+		},
+	})
+
+	assert.Assert(t, cover.IsOpening())
+	assert.Assert(t, !cover.IsOpen())
+	assert.Assert(t, !cover.IsClosed())
+	assert.Assert(t, !cover.IsClosing())
+
+	position, ok := cover.Position()
+	assert.Assert(t, ok)
+	assert.Equal(t, position, 40)
+
+	tilt, ok := cover.TiltPosition()
+	assert.Assert(t, ok)
+	assert.Equal(t, tilt, 20)
+}
+
+func TestCover_SupportedFeatures(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cover := newTestCover("cover.garage", hal.CoverFeatureOpen|hal.CoverFeatureClose)
+
+	assert.ErrorIs(t, cover.SetPosition(ctx, 50), hal.ErrFeatureNotSupported)
+	assert.ErrorIs(t, cover.SetTiltPosition(ctx, 50), hal.ErrFeatureNotSupported)
+	assert.ErrorIs(t, cover.Stop(ctx), hal.ErrFeatureNotSupported)
+	assert.ErrorIs(t, cover.Open(ctx), hal.ErrEntityNotRegistered)
+	assert.ErrorIs(t, cover.SetPosition(ctx, 101), hal.ErrValueOutOfRange)
+}
+
+func TestCover_ServiceCalls(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	cover := newTestCover("cover.blinds", hal.CoverFeatureOpen|hal.CoverFeatureClose|hal.CoverFeatureSetPosition)
+	conn.RegisterEntities(cover)
+
+	// Seed the server so supported_features survives the generated state changes.
+	state := cover.GetState()
+	server.SendEvent(homeassistant.Event{
+		EventType: "state_changed",
+		EventData: homeassistant.EventData{
+			EntityID: cover.GetID(),
+			NewState: &state,
+		},
+	})
+
+	ctx := context.Background()
+
+	assert.NilError(t, cover.SetPosition(ctx, 30))
+	testutil.WaitFor(t, "verify cover moved to position", func() bool {
+		position, _ := cover.Position()
+
+		return cover.IsOpen() && position == 30
+	}, func() {
+		spew.Dump(cover.GetState())
+	})
+
+	assert.NilError(t, cover.Close(ctx))
+	testutil.WaitFor(t, "verify cover closed", cover.IsClosed, func() {
+		spew.Dump(cover.GetState())
+	})
+}
+
+func TestCoverGroup(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	features := hal.CoverFeatureOpen | hal.CoverFeatureClose
+	cover1 := newTestCover("cover.1", features)
+	cover2 := newTestCover("cover.2", features)
+	cg := hal.CoverGroup{cover1, cover2}
+
+	assert.Equal(t, cg.GetID(), "cover.1, cover.2")
+	assert.Assert(t, cg.IsClosed())
+
+	// Not registered yet, so both members fail.
+	assert.ErrorContains(t, cg.Open(context.Background()), "entity not registered")
+
+	conn.RegisterEntities(cover1, cover2)
+
+	assert.NilError(t, cg.Open(context.Background()))
+	testutil.WaitFor(t, "verify covers opened", cg.IsOpen, func() {
+		spew.Dump(cover1.GetState(), cover2.GetState())
+	})
+}
@@ -0,0 +1,150 @@
+package hal
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+)
+
+// EntityEvent is an event fired by an event entity, such as a button press or
+// a doorbell ring.
+type EntityEvent struct {
+	// EventType is the type of event, e.g. "initial_press" or "long_press".
+	EventType string
+
+	// Attributes is the event payload.
+	Attributes map[string]any
+
+	// FiredAt is when the event was fired.
+	FiredAt time.Time
+}
+
+// EventEntity is an entity in the event domain. Its state is the time of the
+// last event and its attributes are the event payload, so two identical
+// events in a row only differ by their timestamp.
+//
+// EventEntity is also an automation: handlers registered with OnEvent are
+// called once for each new event. Changes that are not new events, such as
+// the entity becoming unavailable or being restored after Home Assistant
+// restarts, are ignored.
+type EventEntity struct {
+	*Entity
+
+	// eventMutex guards the fields below.
+	eventMutex sync.RWMutex
+	lastEvent  *EntityEvent
+	newEvent   *EntityEvent
+	handlers   []eventHandler
+}
+
+type eventHandler struct {
+	eventTypes []string
+	fn         func(ctx context.Context, event EntityEvent)
+}
+
+func NewEventEntity(id string) *EventEntity {
+	return &EventEntity{Entity: NewEntity(id)}
+}
+
+// SetState updates the state of the entity and records whether it carries a
+// new event.
+func (e *EventEntity) SetState(state homeassistant.State) {
+	e.Entity.SetState(state)
+
+	e.eventMutex.Lock()
+	defer e.eventMutex.Unlock()
+
+	e.newEvent = nil
+
+	firedAt, err := time.Parse(time.RFC3339Nano, state.State)
+	if err != nil {
+		// Unavailable or unknown
+		return
+	}
+
+	if e.lastEvent != nil && !firedAt.After(e.lastEvent.FiredAt) {
+		return
+	}
+
+	event := &EntityEvent{
+		EventType:  getString(state.Attributes["event_type"]),
+		Attributes: state.Attributes,
+		FiredAt:    firedAt,
+	}
+
+	e.lastEvent = event
+	e.newEvent = event
+}
+
+// Event returns the event delivered by the latest state change. The second
+// return value is false if the latest state change was not a new event.
+func (e *EventEntity) Event() (EntityEvent, bool) {
+	e.eventMutex.RLock()
+	defer e.eventMutex.RUnlock()
+
+	if e.newEvent == nil {
+		return EntityEvent{}, false
+	}
+
+	return *e.newEvent, true
+}
+
+// LastEvent returns the most recent event seen. The second return value is
+// false if no event has been seen since HAL started.
+func (e *EventEntity) LastEvent() (EntityEvent, bool) {
+	e.eventMutex.RLock()
+	defer e.eventMutex.RUnlock()
+
+	if e.lastEvent == nil {
+		return EntityEvent{}, false
+	}
+
+	return *e.lastEvent, true
+}
+
+// EventTypes returns the event types the entity can fire.
+func (e *EventEntity) EventTypes() []string {
+	return getStringOrStringSlice(e.GetState().Attributes["event_types"])
+}
+
+// OnEvent registers a handler that is called for each new event. If event
+// types are given, only events of those types are delivered.
+func (e *EventEntity) OnEvent(handler func(ctx context.Context, event EntityEvent), eventTypes ...string) {
+	e.eventMutex.Lock()
+	defer e.eventMutex.Unlock()
+
+	e.handlers = append(e.handlers, eventHandler{eventTypes: eventTypes, fn: handler})
+}
+
+func (e *EventEntity) Name() string {
+	return e.GetID()
+}
+
+func (e *EventEntity) Entities() Entities {
+	return Entities{e}
+}
+
+func (e *EventEntity) Action(ctx context.Context, _ EntityInterface) {
+	event, ok := e.Event()
+	if !ok {
+		return
+	}
+
+	logger.InfoContext(ctx, "Event received", "event", e.GetID(), "event_type", event.EventType)
+
+	e.eventMutex.RLock()
+	handlers := slices.Clone(e.handlers)
+	e.eventMutex.RUnlock()
+
+	for _, handler := range handlers {
+		if len(handler.eventTypes) > 0 && !slices.Contains(handler.eventTypes, event.EventType) {
+			continue
+		}
+
+		handler.fn(ctx, event)
+	}
+}
@@ -0,0 +1,127 @@
+package hal_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestEventEntity_SetState(t *testing.T) {
+	t.Parallel()
+
+	doorbell := hal.NewEventEntity("event.doorbell")
+	firedAt := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	setState := func(state string) {
+		doorbell.SetState(homeassistant.State{
+			EntityID:   doorbell.GetID(),
+			State:      state,
+			Attributes: map[string]any{"event_type": "ring"},
+		})
+	}
+
+	setState(firedAt.Format(time.RFC3339Nano))
+
+	event, ok := doorbell.Event()
+	assert.Assert(t, ok)
+	assert.Equal(t, event.EventType, "ring")
+	assert.Equal(t, event.FiredAt, firedAt)
+
+	// Becoming unavailable and then being restored is not a new event.
+	setState(homeassistant.StateUnavailable)
+
+	_, ok = doorbell.Event()
+	assert.Assert(t, !ok)
+
+	setState(firedAt.Format(time.RFC3339Nano))
+
+	_, ok = doorbell.Event()
+	assert.Assert(t, !ok)
+
+	last, ok := doorbell.LastEvent()
+	assert.Assert(t, ok)
+	assert.Equal(t, last.FiredAt, firedAt)
+
+	// An identical payload with a new timestamp is.
+	setState(firedAt.Add(time.Second).Format(time.RFC3339Nano))
+
+	_, ok = doorbell.Event()
+	assert.Assert(t, ok)
+}
+
+func TestEventEntity_OnEvent(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	button := hal.NewEventEntity("event.hallway_button")
+
+	var (
+		mutex       sync.Mutex
+		all         []string
+		longPresses int
+	)
+
+	button.OnEvent(func(_ context.Context, event hal.EntityEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		all = append(all, event.EventType)
+	})
+
+	button.OnEvent(func(_ context.Context, _ hal.EntityEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		longPresses++
+	}, "long_press")
+
+	conn.RegisterEntities(button)
+
+	firedAt := time.Now()
+
+	sendEvent := func(state, eventType string) {
+		server.SendEvent(homeassistant.Event{
+			EventType: "state_changed",
+			EventData: homeassistant.EventData{
+				EntityID: button.GetID(),
+				NewState: &homeassistant.State{
+					EntityID:   button.GetID(),
+					State:      state,
+					Attributes: map[string]any{"event_type": eventType},
+				},
+			},
+		})
+	}
+
+	sendEvent(firedAt.Format(time.RFC3339Nano), "initial_press")
+	sendEvent(firedAt.Add(time.Second).Format(time.RFC3339Nano), "initial_press")
+	sendEvent(homeassistant.StateUnavailable, "initial_press")
+	sendEvent(firedAt.Add(time.Second).Format(time.RFC3339Nano), "initial_press")
+	sendEvent(firedAt.Add(2*time.Second).Format(time.RFC3339Nano), "long_press")
+
+	testutil.WaitFor(t, "verify events delivered", func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(all) == 3 && longPresses == 1
+	}, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		spew.Dump(all, longPresses)
+	})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	assert.DeepEqual(t, all, []string{"initial_press", "initial_press", "long_press"})
+}
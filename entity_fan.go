@@ -0,0 +1,141 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// Fan feature flags, as advertised in the supported_features attribute.
+const (
+	FanFeatureSetSpeed = 1 << iota
+	FanFeatureOscillate
+	FanFeatureDirection
+	FanFeaturePresetMode
+)
+
+// Fan directions.
+const (
+	FanDirectionForward = "forward"
+	FanDirectionReverse = "reverse"
+)
+
+// Fan is a ceiling fan, extractor fan or similar.
+type Fan struct {
+	*Entity
+}
+
+func NewFan(id string) *Fan {
+	return &Fan{Entity: NewEntity(id)}
+}
+
+func (f *Fan) IsOn() bool {
+	return f.GetState().State == "on"
+}
+
+func (f *Fan) IsOff() bool {
+	return f.GetState().State == "off"
+}
+
+// Percentage returns the speed of the fan, between 0 and 100. The second
+// return value is false if the fan does not report its speed.
+func (f *Fan) Percentage() (int, bool) {
+	percentage, ok := getFloat(f.GetState().Attributes["percentage"])
+
+	return int(percentage), ok
+}
+
+func (f *Fan) PresetMode() string {
+	return getString(f.GetState().Attributes["preset_mode"])
+}
+
+func (f *Fan) PresetModes() []string {
+	return getStringOrStringSlice(f.GetState().Attributes["preset_modes"])
+}
+
+func (f *Fan) IsOscillating() bool {
+	oscillating, _ := f.GetState().Attributes["oscillating"].(bool)
+
+	return oscillating
+}
+
+// Direction returns the direction the fan is turning, FanDirectionForward or
+// FanDirectionReverse.
+func (f *Fan) Direction() string {
+	return getString(f.GetState().Attributes["direction"])
+}
+
+func (f *Fan) TurnOn(ctx context.Context) error {
+	logger.InfoContext(ctx, "Turning on fan", "fan", f.GetID())
+
+	return f.call(ctx, 0, "turn_on", nil)
+}
+
+func (f *Fan) TurnOff(ctx context.Context) error {
+	logger.InfoContext(ctx, "Turning off fan", "fan", f.GetID())
+
+	return f.call(ctx, 0, "turn_off", nil)
+}
+
+// SetPercentage sets the speed of the fan (0-100). Setting it to 0 turns the
+// fan off.
+func (f *Fan) SetPercentage(ctx context.Context, percentage int) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("%w: fan percentage %d", ErrValueOutOfRange, percentage)
+	}
+
+	logger.InfoContext(ctx, "Setting fan speed", "fan", f.GetID(), "percentage", percentage)
+
+	return f.call(ctx, FanFeatureSetSpeed, "set_percentage", map[string]any{"percentage": percentage})
+}
+
+// SetPresetMode sets the preset mode. The preset must be one of PresetModes.
+func (f *Fan) SetPresetMode(ctx context.Context, preset string) error {
+	if !slices.Contains(f.PresetModes(), preset) {
+		return fmt.Errorf("%w: preset mode %q not in %v", ErrUnsupportedValue, preset, f.PresetModes())
+	}
+
+	logger.InfoContext(ctx, "Setting fan preset mode", "fan", f.GetID(), "preset", preset)
+
+	return f.call(ctx, FanFeaturePresetMode, "set_preset_mode", map[string]any{"preset_mode": preset})
+}
+
+// Oscillate turns oscillation on or off.
+func (f *Fan) Oscillate(ctx context.Context, oscillating bool) error {
+	logger.InfoContext(ctx, "Setting fan oscillation", "fan", f.GetID(), "oscillating", oscillating)
+
+	return f.call(ctx, FanFeatureOscillate, "oscillate", map[string]any{"oscillating": oscillating})
+}
+
+// SetDirection sets the direction of the fan, FanDirectionForward or
+// FanDirectionReverse.
+func (f *Fan) SetDirection(ctx context.Context, direction string) error {
+	if direction != FanDirectionForward && direction != FanDirectionReverse {
+		return fmt.Errorf("%w: fan direction %q", ErrUnsupportedValue, direction)
+	}
+
+	logger.InfoContext(ctx, "Setting fan direction", "fan", f.GetID(), "direction", direction)
+
+	return f.call(ctx, FanFeatureDirection, "set_direction", map[string]any{"direction": direction})
+}
+
+// call calls a fan service if the fan supports the feature it requires. A
+// feature of 0 means the service is always supported.
+func (f *Fan) call(ctx context.Context, feature int, service string, data map[string]any) error {
+	if feature != 0 && !f.supportsFeature(feature) {
+		err := fmt.Errorf("%w: fan.%s", ErrFeatureNotSupported, service)
+		logger.ErrorContext(ctx, "Fan does not support service", "fan", f.GetID(), "error", err)
+
+		return err
+	}
+
+	if err := f.callService("fan", service, data); err != nil {
+		logger.ErrorContext(ctx, "Error calling fan service", "fan", f.GetID(), "service", service, "error", err)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestFan_Validation(t *testing.T) {
+	t.Parallel()
+
+	fan := hal.NewFan("fan.bathroom")
+	fan.SetState(homeassistant.State{
+		EntityID: "fan.bathroom",
+		State:    "on",
+		Attributes: map[string]any{
+			"supported_features": float64(hal.FanFeatureSetSpeed | hal.FanFeaturePresetMode),
+			"percentage":         float64(33),
+			"preset_modes":       []any{"auto", "boost"},
+		},
+	})
+
+	percentage, ok := fan.Percentage()
+	assert.Assert(t, ok)
+	assert.Equal(t, percentage, 33)
+
+	ctx := context.Background()
+
+	assert.ErrorIs(t, fan.SetPercentage(ctx, 101), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, fan.SetPresetMode(ctx, "sleep"), hal.ErrUnsupportedValue)
+	assert.ErrorIs(t, fan.SetDirection(ctx, "sideways"), hal.ErrUnsupportedValue)
+	assert.ErrorIs(t, fan.Oscillate(ctx, true), hal.ErrFeatureNotSupported)
+	assert.ErrorIs(t, fan.SetDirection(ctx, hal.FanDirectionReverse), hal.ErrFeatureNotSupported)
+	assert.ErrorIs(t, fan.SetPercentage(ctx, 50), hal.ErrEntityNotRegistered)
+}
+
+func TestFan_ServiceCalls(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	fan := hal.NewFan("fan.bathroom")
+	conn.RegisterEntities(fan)
+
+	// Seed the server so supported_features survives the generated state changes.
+	state := homeassistant.State{
+		EntityID: fan.GetID(),
+		State:    "off",
+		Attributes: map[string]any{
+			"supported_features": float64(hal.FanFeatureSetSpeed | hal.FanFeatureOscillate),
+		},
+	}
+	server.SendEvent(homeassistant.Event{
+		EventType: "state_changed",
+		EventData: homeassistant.EventData{
+			EntityID: fan.GetID(),
+			NewState: &state,
+		},
+	})
+
+	testutil.WaitFor(t, "verify fan off", fan.IsOff, func() {
+		spew.Dump(fan.GetState())
+	})
+
+	ctx := context.Background()
+
+	assert.NilError(t, fan.SetPercentage(ctx, 66))
+	testutil.WaitFor(t, "verify fan speed set", func() bool {
+		percentage, _ := fan.Percentage()
+
+		return fan.IsOn() && percentage == 66
+	}, func() {
+		spew.Dump(fan.GetState())
+	})
+
+	assert.NilError(t, fan.Oscillate(ctx, true))
+	testutil.WaitFor(t, "verify fan oscillating", fan.IsOscillating, func() {
+		spew.Dump(fan.GetState())
+	})
+
+	assert.NilError(t, fan.SetPercentage(ctx, 0))
+	testutil.WaitFor(t, "verify fan turned off", fan.IsOff, func() {
+		spew.Dump(fan.GetState())
+	})
+}
@@ -0,0 +1,239 @@
+package hal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Group is a typed set of entities that can be queried and controlled
+// together, e.g. "any window open" or "all doors locked":
+//
+//	windows := hal.NewGroup(kitchenWindow, bedroomWindow)
+//	if windows.Any((*hal.BinarySensor).IsOn) { ... }
+//
+// Pass Entities to AutomationConfig.WithEntities to trigger an automation on
+// changes to any member.
+type Group[T EntityInterface] []T
+
+func NewGroup[T EntityInterface](entities ...T) Group[T] {
+	return Group[T](entities)
+}
+
+// Any returns true if pred is true for at least one entity in the group.
+func (g Group[T]) Any(pred func(T) bool) bool {
+	for _, e := range g {
+		if pred(e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// All returns true if pred is true for every entity in the group. An empty
+// group returns true.
+func (g Group[T]) All(pred func(T) bool) bool {
+	for _, e := range g {
+		if !pred(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Count returns the number of entities in the group for which pred is true.
+func (g Group[T]) Count(pred func(T) bool) int {
+	count := 0
+
+	for _, e := range g {
+		if pred(e) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Filter returns a new group containing the entities for which pred is true.
+func (g Group[T]) Filter(pred func(T) bool) Group[T] {
+	var filtered Group[T]
+
+	for _, e := range g {
+		if pred(e) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+// Each calls fn for every entity in the group and collects any errors.
+func (g Group[T]) Each(fn func(T) error) error {
+	return eachMember(g, fn)
+}
+
+// eachMember calls fn for every member of a group and collects any errors. It
+// is shared by the group types so that they report errors the same way.
+func eachMember[T any](members []T, fn func(T) error) error {
+	var errs []error
+
+	for _, member := range members {
+		if err := fn(member); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return joinErrors(errs)
+}
+
+// joinErrors returns nil for no errors, the error itself for one, and the
+// errors joined otherwise.
+func joinErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	return errors.Join(errs...)
+}
+
+// Entities returns the members of the group, for registering them with a
+// connection or using them as automation triggers.
+func (g Group[T]) Entities() Entities {
+	entities := make(Entities, len(g))
+	for i, e := range g {
+		entities[i] = e
+	}
+
+	return entities
+}
+
+// IDs returns the entity IDs of the members of the group.
+func (g Group[T]) IDs() []string {
+	ids := make([]string, len(g))
+	for i, e := range g {
+		ids[i] = e.GetID()
+	}
+
+	return ids
+}
+
+// Interfaces for the command signatures used by the entity types, so that a
+// group can fan out a command to members of different types.
+type (
+	lightTurnOner interface {
+		TurnOnContext(ctx context.Context, attributes ...map[string]any) error
+	}
+	contextTurnOner interface {
+		TurnOnContext(ctx context.Context) error
+	}
+	turnOnerWithContext interface {
+		TurnOn(ctx context.Context) error
+	}
+	attributeTurnOner interface {
+		TurnOn(attributes ...map[string]any) error
+	}
+	turnOner interface {
+		TurnOn() error
+	}
+	contextTurnOffer interface {
+		TurnOffContext(ctx context.Context) error
+	}
+	turnOfferWithContext interface {
+		TurnOff(ctx context.Context) error
+	}
+	turnOffer interface {
+		TurnOff() error
+	}
+	contextToggler interface {
+		ToggleContext(ctx context.Context) error
+	}
+	toggler interface {
+		Toggle() error
+	}
+	locker interface {
+		Lock(ctx context.Context) error
+	}
+	unlocker interface {
+		Unlock(ctx context.Context, code string) error
+	}
+)
+
+// TurnOn turns on every entity in the group. Members that cannot be turned on
+// return ErrFeatureNotSupported; the remaining members are still turned on.
+func (g Group[T]) TurnOn(ctx context.Context) error {
+	return g.Each(func(e T) error {
+		switch v := any(e).(type) {
+		case lightTurnOner:
+			return v.TurnOnContext(ctx)
+		case contextTurnOner:
+			return v.TurnOnContext(ctx)
+		case turnOnerWithContext:
+			return v.TurnOn(ctx)
+		case attributeTurnOner:
+			return v.TurnOn()
+		case turnOner:
+			return v.TurnOn()
+		default:
+			return unsupportedGroupCommand(e, "turn on")
+		}
+	})
+}
+
+// TurnOff turns off every entity in the group.
+func (g Group[T]) TurnOff(ctx context.Context) error {
+	return g.Each(func(e T) error {
+		switch v := any(e).(type) {
+		case contextTurnOffer:
+			return v.TurnOffContext(ctx)
+		case turnOfferWithContext:
+			return v.TurnOff(ctx)
+		case turnOffer:
+			return v.TurnOff()
+		default:
+			return unsupportedGroupCommand(e, "turn off")
+		}
+	})
+}
+
+// Toggle toggles every entity in the group individually.
+func (g Group[T]) Toggle(ctx context.Context) error {
+	return g.Each(func(e T) error {
+		switch v := any(e).(type) {
+		case contextToggler:
+			return v.ToggleContext(ctx)
+		case toggler:
+			return v.Toggle()
+		default:
+			return unsupportedGroupCommand(e, "toggle")
+		}
+	})
+}
+
+// Lock locks every lock in the group.
+func (g Group[T]) Lock(ctx context.Context) error {
+	return g.Each(func(e T) error {
+		if v, ok := any(e).(locker); ok {
+			return v.Lock(ctx)
+		}
+
+		return unsupportedGroupCommand(e, "lock")
+	})
+}
+
+// Unlock unlocks every lock in the group with the given code, which may be
+// empty.
+func (g Group[T]) Unlock(ctx context.Context, code string) error {
+	return g.Each(func(e T) error {
+		if v, ok := any(e).(unlocker); ok {
+			return v.Unlock(ctx, code)
+		}
+
+		return unsupportedGroupCommand(e, "unlock")
+	})
+}
+
+func unsupportedGroupCommand(e EntityInterface, command string) error {
+	return fmt.Errorf("%w: %s cannot %s", ErrFeatureNotSupported, e.GetID(), command)
+}
@@ -0,0 +1,117 @@
+package hal_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestGroup_Predicates(t *testing.T) {
+	t.Parallel()
+
+	window1 := hal.NewBinarySensor("binary_sensor.window_1")
+	window2 := hal.NewBinarySensor("binary_sensor.window_2")
+	window3 := hal.NewBinarySensor("binary_sensor.window_3")
+	windows := hal.NewGroup(window1, window2, window3)
+
+	window1.SetState(homeassistant.State{EntityID: "binary_sensor.window_1", State: "off"})
+	window2.SetState(homeassistant.State{EntityID: "binary_sensor.window_2", State: "on"})
+	window3.SetState(homeassistant.State{EntityID: "binary_sensor.window_3", State: "on"})
+
+	assert.Assert(t, windows.Any((*hal.BinarySensor).IsOn))
+	assert.Assert(t, !windows.All((*hal.BinarySensor).IsOn))
+	assert.Equal(t, windows.Count((*hal.BinarySensor).IsOn), 2)
+	assert.DeepEqual(t, windows.Filter((*hal.BinarySensor).IsOn).IDs(), []string{
+		"binary_sensor.window_2",
+		"binary_sensor.window_3",
+	})
+	assert.Equal(t, len(windows.Entities()), 3)
+
+	empty := hal.Group[*hal.BinarySensor]{}
+	assert.Assert(t, !empty.Any((*hal.BinarySensor).IsOn))
+	assert.Assert(t, empty.All((*hal.BinarySensor).IsOn))
+}
+
+func TestGroup_UnsupportedCommand(t *testing.T) {
+	t.Parallel()
+
+	sensors := hal.NewGroup(hal.NewBinarySensor("binary_sensor.1"), hal.NewBinarySensor("binary_sensor.2"))
+
+	err := sensors.TurnOn(context.Background())
+	assert.Assert(t, errors.Is(err, hal.ErrFeatureNotSupported))
+	assert.ErrorContains(t, err, "binary_sensor.2 cannot turn on")
+}
+
+func TestGroup_FanOut(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	devices := hal.NewGroup[hal.EntityInterface](
+		hal.NewLight("light.1"),
+		hal.NewSwitch("switch.1"),
+		hal.NewFan("fan.1"),
+	)
+	conn.RegisterEntities(devices...)
+
+	isOn := func(e hal.EntityInterface) bool { return e.GetState().State == "on" }
+	isOff := func(e hal.EntityInterface) bool { return e.GetState().State == "off" }
+
+	assert.NilError(t, devices.TurnOn(context.Background()))
+	testutil.WaitFor(t, "verify devices turned on", func() bool {
+		return devices.All(isOn)
+	}, func() {
+		spew.Dump(devices)
+	})
+
+	assert.NilError(t, devices.TurnOff(context.Background()))
+	testutil.WaitFor(t, "verify devices turned off", func() bool {
+		return devices.All(isOff)
+	}, func() {
+		spew.Dump(devices)
+	})
+}
+
+func TestGroup_TriggersAutomation(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	doors := hal.NewGroup(hal.NewLock("lock.front"), hal.NewLock("lock.back"))
+	conn.RegisterEntities(doors.Entities()...)
+
+	var unlocked atomic.Int32
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("doors.unlocked").
+			WithEntities(doors.Entities()...).
+			WithAction(func(_ context.Context, _ hal.EntityInterface) {
+				if !doors.All((*hal.Lock).IsLocked) {
+					unlocked.Add(1)
+				}
+			}),
+	)
+
+	server.SendEvent(homeassistant.Event{
+		EventData: homeassistant.EventData{
+			EntityID: "lock.back",
+			NewState: &homeassistant.State{EntityID: "lock.back", State: "unlocked"},
+		},
+	})
+
+	testutil.WaitFor(t, "verify automation was triggered", func() bool {
+		return unlocked.Load() == 1
+	}, func() {
+		spew.Dump(unlocked.Load())
+	})
+}
@@ -0,0 +1,40 @@
+package hal
+
+import (
+	"context"
+	"time"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// InputButton is a button helper. Its state is the time it was last pressed.
+type InputButton struct {
+	*Entity
+}
+
+func NewInputButton(id string) *InputButton {
+	return &InputButton{Entity: NewEntity(id)}
+}
+
+// LastPressed returns when the button was last pressed. The second return
+// value is false if it has never been pressed.
+func (b *InputButton) LastPressed() (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339Nano, b.GetState().State)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+func (b *InputButton) Press(ctx context.Context) error {
+	logger.InfoContext(ctx, "Pressing button", "input_button", b.GetID())
+
+	if err := b.callService("input_button", "press", nil); err != nil {
+		logger.ErrorContext(ctx, "Error pressing button", "input_button", b.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package hal
+
+import (
+	"context"
+	"time"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// Formats used by input_datetime for its state and service data.
+const (
+	inputDatetimeFormat = "2006-01-02 15:04:05"
+	inputDateFormat     = "2006-01-02"
+	inputTimeFormat     = "15:04:05"
+)
+
+// InputDatetime is a date and/or time helper.
+type InputDatetime struct {
+	*Entity
+}
+
+func NewInputDatetime(id string) *InputDatetime {
+	return &InputDatetime{Entity: NewEntity(id)}
+}
+
+// HasDate returns true if the helper holds a date.
+func (d *InputDatetime) HasDate() bool {
+	hasDate, _ := d.GetState().Attributes["has_date"].(bool)
+
+	return hasDate
+}
+
+// HasTime returns true if the helper holds a time of day.
+func (d *InputDatetime) HasTime() bool {
+	hasTime, _ := d.GetState().Attributes["has_time"].(bool)
+
+	return hasTime
+}
+
+// Value returns the date and/or time in the time zone from Config.TimeZone,
+// which should match Home Assistant's. For time-only helpers the date part is
+// zero (January 1, year 0); for date-only helpers the time is midnight. The
+// second return value is false if the state cannot be parsed.
+func (d *InputDatetime) Value() (time.Time, bool) {
+	state := d.GetState().State
+
+	for _, layout := range []string{inputDatetimeFormat, inputDateFormat, inputTimeFormat} {
+		if t, err := time.ParseInLocation(layout, state, d.location()); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// SetDatetime sets the helper to the given time, converted to the time zone
+// from Config.TimeZone. Only the parts the helper holds (date, time or both)
+// are sent.
+func (d *InputDatetime) SetDatetime(ctx context.Context, t time.Time) error {
+	t = t.In(d.location())
+
+	var data map[string]any
+
+	switch {
+	case d.HasDate() && !d.HasTime():
+		data = map[string]any{"date": t.Format(inputDateFormat)}
+	case d.HasTime() && !d.HasDate():
+		data = map[string]any{"time": t.Format(inputTimeFormat)}
+	default:
+		data = map[string]any{"datetime": t.Format(inputDatetimeFormat)}
+	}
+
+	logger.InfoContext(ctx, "Setting date/time", "input_datetime", d.GetID(), "value", data)
+
+	if err := d.callService("input_datetime", "set_datetime", data); err != nil {
+		logger.ErrorContext(ctx, "Error setting date/time", "input_datetime", d.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
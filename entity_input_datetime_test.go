@@ -0,0 +1,119 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestInputDatetime_Value(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		state string
+		want  time.Time
+		ok    bool
+	}{
+		{name: "date and time", state: "2024-03-01 06:30:00", want: time.Date(2024, 3, 1, 6, 30, 0, 0, time.Local), ok: true},
+		{name: "date only", state: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), ok: true},
+		{name: "time only", state: "06:30:00", want: time.Date(0, 1, 1, 6, 30, 0, 0, time.Local), ok: true},
+		{name: "unknown", state: "unknown", ok: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dt := hal.NewInputDatetime("input_datetime.wake_up")
+			dt.SetState(homeassistant.State{State: tc.state})
+
+			got, ok := dt.Value()
+			assert.Equal(t, ok, tc.ok)
+			assert.Assert(t, got.Equal(tc.want), "got %v, want %v", got, tc.want)
+		})
+	}
+}
+
+func TestInputDatetime_SetDatetime(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	wakeUp := hal.NewInputDatetime("input_datetime.wake_up")
+	conn.RegisterEntities(wakeUp)
+
+	wakeUp.SetState(homeassistant.State{
+		EntityID:   "input_datetime.wake_up",
+		State:      "07:00:00",
+		Attributes: map[string]any{"has_date": false, "has_time": true},
+	})
+
+	assert.NilError(t, wakeUp.SetDatetime(context.Background(), time.Date(2024, 3, 1, 6, 15, 0, 0, time.Local)))
+	testutil.WaitFor(t, "verify time set", func() bool {
+		return wakeUp.GetState().State == "06:15:00"
+	}, func() {
+		spew.Dump(wakeUp.GetState())
+	})
+}
+
+func TestInputDatetime_TimeZone(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServerWithConfig(t, hal.Config{
+		TimeZone: "Asia/Tokyo",
+	})
+	defer cleanup()
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NilError(t, err)
+
+	alarm := hal.NewInputDatetime("input_datetime.alarm")
+	conn.RegisterEntities(alarm)
+
+	alarm.SetState(homeassistant.State{
+		EntityID:   "input_datetime.alarm",
+		State:      "2024-03-01 07:00:00",
+		Attributes: map[string]any{"has_date": true, "has_time": true},
+	})
+
+	got, ok := alarm.Value()
+	assert.Assert(t, ok)
+	assert.Assert(t, got.Equal(time.Date(2024, 3, 1, 7, 0, 0, 0, tokyo)), "got %v", got)
+
+	assert.NilError(t, alarm.SetDatetime(context.Background(), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+	testutil.WaitFor(t, "verify date/time set in the configured time zone", func() bool {
+		return alarm.GetState().State == "2024-03-01 09:00:00"
+	}, func() {
+		spew.Dump(alarm.GetState())
+	})
+}
+
+func TestInputButton_Press(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	button := hal.NewInputButton("input_button.doorbell")
+	conn.RegisterEntities(button)
+
+	_, pressed := button.LastPressed()
+	assert.Assert(t, !pressed)
+
+	assert.NilError(t, button.Press(context.Background()))
+	testutil.WaitFor(t, "verify button pressed", func() bool {
+		_, pressed := button.LastPressed()
+
+		return pressed
+	}, func() {
+		spew.Dump(button.GetState())
+	})
+}
@@ -0,0 +1,77 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// InputNumber is a numeric helper, such as a slider on a dashboard.
+type InputNumber struct {
+	*Entity
+}
+
+func NewInputNumber(id string) *InputNumber {
+	return &InputNumber{Entity: NewEntity(id)}
+}
+
+// Value returns the current value. The second return value is false if the
+// state is not a number (e.g. the entity is unavailable).
+func (n *InputNumber) Value() (float64, bool) {
+	v, err := strconv.ParseFloat(n.GetState().State, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
+
+// Min returns the lowest value the helper accepts.
+func (n *InputNumber) Min() (float64, bool) {
+	return getFloat(n.GetState().Attributes["min"])
+}
+
+// Max returns the highest value the helper accepts.
+func (n *InputNumber) Max() (float64, bool) {
+	return getFloat(n.GetState().Attributes["max"])
+}
+
+// Step returns the increment between values.
+func (n *InputNumber) Step() (float64, bool) {
+	return getFloat(n.GetState().Attributes["step"])
+}
+
+// SetValue sets the value. It must be within Min and Max.
+func (n *InputNumber) SetValue(ctx context.Context, value float64) error {
+	if err := n.validate(value); err != nil {
+		logger.ErrorContext(ctx, "Invalid number", "input_number", n.GetID(), "error", err)
+
+		return err
+	}
+
+	logger.InfoContext(ctx, "Setting number", "input_number", n.GetID(), "value", value)
+
+	if err := n.callService("input_number", "set_value", map[string]any{"value": value}); err != nil {
+		logger.ErrorContext(ctx, "Error setting number", "input_number", n.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// validate checks the value against the min and max attributes advertised by
+// the entity.
+func (n *InputNumber) validate(value float64) error {
+	if minValue, ok := n.Min(); ok && value < minValue {
+		return fmt.Errorf("%w: %v is below min %v", ErrValueOutOfRange, value, minValue)
+	}
+
+	if maxValue, ok := n.Max(); ok && value > maxValue {
+		return fmt.Errorf("%w: %v is above max %v", ErrValueOutOfRange, value, maxValue)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestInputNumber(t *testing.T) {
+	t.Parallel()
+
+	number := hal.NewInputNumber("input_number.night_brightness")
+	number.SetState(homeassistant.State{
+		EntityID:   "input_number.night_brightness",
+		State:      "20.0",
+		Attributes: map[string]any{"min": float64(0), "max": float64(100), "step": float64(5)},
+	})
+
+	value, ok := number.Value()
+	assert.Assert(t, ok)
+	assert.Equal(t, value, 20.0)
+
+	step, ok := number.Step()
+	assert.Assert(t, ok)
+	assert.Equal(t, step, 5.0)
+
+	ctx := context.Background()
+
+	assert.ErrorIs(t, number.SetValue(ctx, 101), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, number.SetValue(ctx, -1), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, number.SetValue(ctx, 50), hal.ErrEntityNotRegistered)
+
+	number.SetState(homeassistant.State{State: "unavailable"})
+
+	_, ok = number.Value()
+	assert.Assert(t, !ok)
+}
+
+func TestInputNumber_SetValue(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	number := hal.NewInputNumber("input_number.night_brightness")
+	conn.RegisterEntities(number)
+
+	assert.NilError(t, number.SetValue(context.Background(), 35.5))
+	testutil.WaitFor(t, "verify value set", func() bool {
+		value, _ := number.Value()
+
+		return value == 35.5
+	}, func() {
+		spew.Dump(number.GetState())
+	})
+}
@@ -0,0 +1,48 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// InputSelect is a dropdown helper with a fixed list of options.
+type InputSelect struct {
+	*Entity
+}
+
+func NewInputSelect(id string) *InputSelect {
+	return &InputSelect{Entity: NewEntity(id)}
+}
+
+// Option returns the selected option.
+func (s *InputSelect) Option() string {
+	return s.GetState().State
+}
+
+// Options returns the options that can be selected.
+func (s *InputSelect) Options() []string {
+	return getStringOrStringSlice(s.GetState().Attributes["options"])
+}
+
+// SelectOption selects the given option. It must be one of Options.
+func (s *InputSelect) SelectOption(ctx context.Context, option string) error {
+	if !slices.Contains(s.Options(), option) {
+		err := fmt.Errorf("%w: option %q not in %v", ErrUnsupportedValue, option, s.Options())
+		logger.ErrorContext(ctx, "Invalid option", "input_select", s.GetID(), "error", err)
+
+		return err
+	}
+
+	logger.InfoContext(ctx, "Selecting option", "input_select", s.GetID(), "option", option)
+
+	if err := s.callService("input_select", "select_option", map[string]any{"option": option}); err != nil {
+		logger.ErrorContext(ctx, "Error selecting option", "input_select", s.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestInputSelect(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	mode := hal.NewInputSelect("input_select.house_mode")
+	conn.RegisterEntities(mode)
+
+	mode.SetState(homeassistant.State{
+		EntityID:   "input_select.house_mode",
+		State:      "home",
+		Attributes: map[string]any{"options": []any{"home", "away", "night"}},
+	})
+
+	assert.Equal(t, mode.Option(), "home")
+	assert.DeepEqual(t, mode.Options(), []string{"home", "away", "night"})
+
+	ctx := context.Background()
+
+	assert.ErrorIs(t, mode.SelectOption(ctx, "holiday"), hal.ErrUnsupportedValue)
+
+	assert.NilError(t, mode.SelectOption(ctx, "away"))
+	testutil.WaitFor(t, "verify option selected", func() bool {
+		return mode.Option() == "away"
+	}, func() {
+		spew.Dump(mode.GetState())
+	})
+}
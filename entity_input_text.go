@@ -0,0 +1,68 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// InputText is a free text helper.
+type InputText struct {
+	*Entity
+}
+
+func NewInputText(id string) *InputText {
+	return &InputText{Entity: NewEntity(id)}
+}
+
+func (t *InputText) Value() string {
+	return t.GetState().State
+}
+
+// SetValue sets the text. Its length must be within the min and max
+// attributes, and it must match the pattern attribute if one is set.
+func (t *InputText) SetValue(ctx context.Context, value string) error {
+	if err := t.validate(value); err != nil {
+		logger.ErrorContext(ctx, "Invalid text", "input_text", t.GetID(), "error", err)
+
+		return err
+	}
+
+	logger.InfoContext(ctx, "Setting text", "input_text", t.GetID(), "value", value)
+
+	if err := t.callService("input_text", "set_value", map[string]any{"value": value}); err != nil {
+		logger.ErrorContext(ctx, "Error setting text", "input_text", t.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (t *InputText) validate(value string) error {
+	attributes := t.GetState().Attributes
+	length := float64(len([]rune(value)))
+
+	if minLength, ok := getFloat(attributes["min"]); ok && length < minLength {
+		return fmt.Errorf("%w: text is shorter than %v characters", ErrValueOutOfRange, minLength)
+	}
+
+	if maxLength, ok := getFloat(attributes["max"]); ok && length > maxLength {
+		return fmt.Errorf("%w: text is longer than %v characters", ErrValueOutOfRange, maxLength)
+	}
+
+	if pattern := getString(attributes["pattern"]); pattern != "" {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+
+		if !re.MatchString(value) {
+			return fmt.Errorf("%w: text does not match pattern %q", ErrUnsupportedValue, pattern)
+		}
+	}
+
+	return nil
+}
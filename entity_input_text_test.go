@@ -0,0 +1,41 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestInputText(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	code := hal.NewInputText("input_text.guest_code")
+	conn.RegisterEntities(code)
+
+	code.SetState(homeassistant.State{
+		EntityID:   "input_text.guest_code",
+		State:      "1234",
+		Attributes: map[string]any{"min": float64(4), "max": float64(6), "pattern": "[0-9]+"},
+	})
+
+	ctx := context.Background()
+
+	assert.ErrorIs(t, code.SetValue(ctx, "123"), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, code.SetValue(ctx, "1234567"), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, code.SetValue(ctx, "12ab"), hal.ErrUnsupportedValue)
+
+	assert.NilError(t, code.SetValue(ctx, "98765"))
+	testutil.WaitFor(t, "verify text set", func() bool {
+		return code.Value() == "98765"
+	}, func() {
+		spew.Dump(code.GetState())
+	})
+}
@@ -2,7 +2,8 @@ package hal
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/dansimau/hal/hassws"
@@ -41,6 +42,75 @@ func (l *Light) IsOn() bool {
 	return l.Entity.GetState().State == "on"
 }
 
+func (l *Light) IsOff() bool {
+	return l.Entity.GetState().State == "off"
+}
+
+// ColorMode returns the color mode the light is currently in, e.g.
+// ColorModeColorTemp or ColorModeRGB.
+func (l *Light) ColorMode() string {
+	return getString(l.GetState().Attributes["color_mode"])
+}
+
+// SupportedColorModes returns the color modes the light supports.
+func (l *Light) SupportedColorModes() []string {
+	return getStringOrStringSlice(l.GetState().Attributes["supported_color_modes"])
+}
+
+// ColorTempKelvin returns the color temperature, in Kelvin. The second return
+// value is false if the light is off or showing a color.
+func (l *Light) ColorTempKelvin() (int, bool) {
+	kelvin, ok := getFloat(l.GetState().Attributes["color_temp_kelvin"])
+
+	return int(kelvin), ok
+}
+
+// MinColorTempKelvin returns the warmest color temperature the light supports.
+func (l *Light) MinColorTempKelvin() (int, bool) {
+	kelvin, ok := getFloat(l.GetState().Attributes["min_color_temp_kelvin"])
+
+	return int(kelvin), ok
+}
+
+// MaxColorTempKelvin returns the coolest color temperature the light supports.
+func (l *Light) MaxColorTempKelvin() (int, bool) {
+	kelvin, ok := getFloat(l.GetState().Attributes["max_color_temp_kelvin"])
+
+	return int(kelvin), ok
+}
+
+// RGB returns the color as red, green and blue values. The last return value
+// is false if the light does not report a color.
+func (l *Light) RGB() (r, g, b uint8, ok bool) {
+	values, ok := l.GetState().Attributes["rgb_color"].([]any)
+	if !ok || len(values) != 3 {
+		return 0, 0, 0, false
+	}
+
+	var rgb [3]uint8
+
+	for i, v := range values {
+		f, ok := getFloat(v)
+		if !ok {
+			return 0, 0, 0, false
+		}
+
+		rgb[i] = uint8(f)
+	}
+
+	return rgb[0], rgb[1], rgb[2], true
+}
+
+// Effect returns the current effect, if any.
+func (l *Light) Effect() string {
+	return getString(l.GetState().Attributes["effect"])
+}
+
+// Effects returns the effects the light supports.
+func (l *Light) Effects() []string {
+	return getStringOrStringSlice(l.GetState().Attributes["effect_list"])
+}
+
 func (l *Light) TurnOn(attributes ...map[string]any) error {
 	entityID := l.GetID()
 	if l.connection == nil {
@@ -61,6 +131,12 @@ func (l *Light) TurnOn(attributes ...map[string]any) error {
 		}
 	}
 
+	if err := l.validateTurnOn(data); err != nil {
+		logger.Error("Invalid light attributes", entityID, "error", err)
+
+		return err
+	}
+
 	_, err := l.connection.CallService(hassws.CallServiceRequest{
 		Type:    hassws.MessageTypeCallService,
 		Domain:  "light",
@@ -96,6 +172,12 @@ func (l *Light) TurnOnContext(ctx context.Context, attributes ...map[string]any)
 		}
 	}
 
+	if err := l.validateTurnOn(data); err != nil {
+		logger.ErrorContext(ctx, "Invalid light attributes", "error", err)
+
+		return err
+	}
+
 	_, err := l.connection.CallService(hassws.CallServiceRequest{
 		Type:    hassws.MessageTypeCallService,
 		Domain:  "light",
@@ -169,6 +251,13 @@ func (l *Light) TurnOffContext(ctx context.Context) error {
 	return nil
 }
 
+// ColorModeMixed is the color mode reported by a LightGroup whose lights are
+// in different color modes.
+const ColorModeMixed = "mixed"
+
+// LightGroup treats a set of lights as one. Lights that are registered on the
+// same connection are controlled with a single service call, so that they
+// change at the same time.
 type LightGroup []LightInterface
 
 func (lg LightGroup) BindConnection(connection *Connection) {
@@ -182,28 +271,116 @@ func (lg LightGroup) GetID() string {
 		return "(empty light group)"
 	}
 
-	ids := make([]string, len(lg))
-	for i, l := range lg {
-		ids[i] = l.GetID()
-	}
-
-	return strings.Join(ids, ", ")
+	return strings.Join(lg.entityIDs(), ", ")
 }
 
+// GetBrightness returns the mean brightness of the lights that are on, or 0
+// if none are.
 func (lg LightGroup) GetBrightness() float64 {
-	if len(lg) == 0 {
+	var (
+		total float64
+		count int
+	)
+
+	for _, l := range lg {
+		if l.IsOn() {
+			total += l.GetBrightness()
+			count++
+		}
+	}
+
+	if count == 0 {
 		return 0
 	}
 
-	return lg[0].GetBrightness()
+	return total / float64(count)
 }
 
+// MaxBrightness returns the brightness of the brightest light that is on.
+func (lg LightGroup) MaxBrightness() float64 {
+	var brightest float64
+
+	for _, l := range lg {
+		if l.IsOn() {
+			brightest = max(brightest, l.GetBrightness())
+		}
+	}
+
+	return brightest
+}
+
+// ColorMode returns the color mode shared by the lights that are on,
+// ColorModeMixed if they differ, or an empty string if none are on.
+func (lg LightGroup) ColorMode() string {
+	var mode string
+
+	for _, l := range lg {
+		if !l.IsOn() {
+			continue
+		}
+
+		lightMode := getString(l.GetState().Attributes["color_mode"])
+
+		switch mode {
+		case "":
+			mode = lightMode
+		case lightMode:
+		default:
+			return ColorModeMixed
+		}
+	}
+
+	return mode
+}
+
+// GetState returns the aggregate state of the group, as Home Assistant
+// reports it for light groups: "on" if any light is on, "unavailable" if all
+// lights are unavailable, and "off" otherwise. The brightness and color_mode
+// attributes are those of the lights that are on, and entity_id lists the
+// members.
 func (lg LightGroup) GetState() homeassistant.State {
 	if len(lg) == 0 {
 		return homeassistant.State{}
 	}
 
-	return lg[0].GetState()
+	state := homeassistant.State{
+		State: "off",
+		Attributes: map[string]any{
+			"entity_id": lg.entityIDs(),
+		},
+	}
+
+	unavailable := 0
+
+	for _, l := range lg {
+		memberState := l.GetState()
+
+		if memberState.State == homeassistant.StateUnavailable {
+			unavailable++
+		}
+
+		if memberState.LastChanged.After(state.LastChanged) {
+			state.LastChanged = memberState.LastChanged
+		}
+
+		if memberState.LastUpdated.After(state.LastUpdated) {
+			state.LastUpdated = memberState.LastUpdated
+		}
+	}
+
+	switch {
+	case lg.AnyOn():
+		state.State = "on"
+		state.Attributes["brightness"] = lg.GetBrightness()
+
+		if colorMode := lg.ColorMode(); colorMode != "" {
+			state.Attributes["color_mode"] = colorMode
+		}
+	case unavailable == len(lg):
+		state.State = homeassistant.StateUnavailable
+	}
+
+	return state
 }
 
 func (lg LightGroup) SetState(state homeassistant.State) {
@@ -212,7 +389,14 @@ func (lg LightGroup) SetState(state homeassistant.State) {
 	}
 }
 
+// IsOn returns true if all lights in the group are on. It is the same as
+// AllOn.
 func (lg LightGroup) IsOn() bool {
+	return lg.AllOn()
+}
+
+// AllOn returns true if all lights in the group are on.
+func (lg LightGroup) AllOn() bool {
 	for _, l := range lg {
 		if !l.IsOn() {
 			return false
@@ -222,9 +406,22 @@ func (lg LightGroup) IsOn() bool {
 	return true
 }
 
-func (lg LightGroup) IsOff() bool {
+// AnyOn returns true if at least one light in the group is on.
+func (lg LightGroup) AnyOn() bool {
 	for _, l := range lg {
 		if l.IsOn() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOff returns true if all lights in the group are off. Like IsOn, it is
+// false while any light is unavailable.
+func (lg LightGroup) IsOff() bool {
+	for _, l := range lg {
+		if l.GetState().State != "off" {
 			return false
 		}
 	}
@@ -233,73 +430,141 @@ func (lg LightGroup) IsOff() bool {
 }
 
 func (lg LightGroup) TurnOn(attributes ...map[string]any) error {
-	var errs []error
+	return lg.TurnOnContext(context.Background(), attributes...)
+}
 
-	for _, l := range lg {
-		if err := l.TurnOn(attributes...); err != nil {
-			errs = append(errs, err)
-		}
-	}
+func (lg LightGroup) TurnOnContext(ctx context.Context, attributes ...map[string]any) error {
+	data := map[string]any{}
 
-	if len(errs) == 1 {
-		return errs[0]
-	} else if len(errs) > 1 {
-		return errors.Join(errs...)
+	for _, attribute := range attributes {
+		for k, v := range attribute {
+			data[k] = v
+		}
 	}
 
-	return nil
+	return lg.call(ctx, "turn_on", data, func(l LightInterface) error {
+		return l.TurnOnContext(ctx, attributes...)
+	})
 }
 
-func (lg LightGroup) TurnOnContext(ctx context.Context, attributes ...map[string]any) error {
-	var errs []error
+// TurnOnEachContext turns on every light with its own attributes, as returned
+// by fn. Lights that are given the same attributes are still turned on with a
+// single service call.
+func (lg LightGroup) TurnOnEachContext(ctx context.Context, fn func(LightInterface) map[string]any) error {
+	var (
+		keys   []string
+		groups = map[string]LightGroup{}
+		attrs  = map[string]map[string]any{}
+	)
 
 	for _, l := range lg {
-		if err := l.TurnOnContext(ctx, attributes...); err != nil {
-			errs = append(errs, err)
+		attributes := fn(l)
+
+		// encoding/json sorts map keys, so equal attributes have equal keys.
+		keyBytes, err := json.Marshal(attributes)
+		if err != nil {
+			return fmt.Errorf("invalid attributes for %s: %w", l.GetID(), err)
 		}
-	}
 
-	if len(errs) == 1 {
-		return errs[0]
-	} else if len(errs) > 1 {
-		return errors.Join(errs...)
-	}
+		key := string(keyBytes)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+			attrs[key] = attributes
+		}
 
-	return nil
-}
+		groups[key] = append(groups[key], l)
+	}
 
-func (lg LightGroup) TurnOff() error {
 	var errs []error
 
-	for _, l := range lg {
-		if err := l.TurnOff(); err != nil {
+	for _, key := range keys {
+		if err := groups[key].TurnOnContext(ctx, attrs[key]); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
-	if len(errs) == 1 {
-		return errs[0]
-	} else if len(errs) > 1 {
-		return errors.Join(errs...)
-	}
+	return joinErrors(errs)
+}
 
-	return nil
+func (lg LightGroup) TurnOff() error {
+	return lg.TurnOffContext(context.Background())
 }
 
 func (lg LightGroup) TurnOffContext(ctx context.Context) error {
-	var errs []error
+	return lg.call(ctx, "turn_off", nil, func(l LightInterface) error {
+		return l.TurnOffContext(ctx)
+	})
+}
 
-	for _, l := range lg {
-		if err := l.TurnOffContext(ctx); err != nil {
+// call calls a light service for the whole group. Lights registered on the
+// same connection are batched into one call; other members (e.g. nested
+// groups, custom light types or lights that are not registered) are handled
+// individually by fallback.
+func (lg LightGroup) call(ctx context.Context, service string, data map[string]any, fallback func(LightInterface) error) error {
+	var (
+		errs        []error
+		connections []*Connection
+		batches     = map[*Connection][]string{}
+	)
+
+	for _, member := range lg {
+		light, ok := member.(*Light)
+		if !ok || light.connection == nil {
+			if err := fallback(member); err != nil {
+				errs = append(errs, err)
+			}
+
+			continue
+		}
+
+		if service == "turn_on" {
+			if err := light.validateTurnOn(data); err != nil {
+				logger.ErrorContext(ctx, "Invalid light attributes", "light", light.GetID(), "error", err)
+				errs = append(errs, err)
+
+				continue
+			}
+		}
+
+		if _, ok := batches[light.connection]; !ok {
+			connections = append(connections, light.connection)
+		}
+
+		batches[light.connection] = append(batches[light.connection], light.GetID())
+	}
+
+	for _, connection := range connections {
+		entityIDs := batches[connection]
+
+		serviceData := map[string]any{
+			"entity_id": entityIDs,
+		}
+
+		for k, v := range data {
+			serviceData[k] = v
+		}
+
+		logger.InfoContext(ctx, "Calling light service for group", "service", service, "lights", entityIDs)
+
+		if _, err := connection.CallService(hassws.CallServiceRequest{
+			Type:    hassws.MessageTypeCallService,
+			Domain:  "light",
+			Service: service,
+			Data:    serviceData,
+		}); err != nil {
+			logger.ErrorContext(ctx, "Error calling light service for group", "service", service, "error", err)
 			errs = append(errs, err)
 		}
 	}
 
-	if len(errs) == 1 {
-		return errs[0]
-	} else if len(errs) > 1 {
-		return errors.Join(errs...)
+	return joinErrors(errs)
+}
+
+func (lg LightGroup) entityIDs() []string {
+	ids := make([]string, len(lg))
+	for i, l := range lg {
+		ids[i] = l.GetID()
 	}
 
-	return nil
+	return ids
 }
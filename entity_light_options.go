@@ -0,0 +1,161 @@
+package hal
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Light color modes, as reported in the color_mode and supported_color_modes
+// attributes.
+const (
+	ColorModeOnOff      = "onoff"
+	ColorModeBrightness = "brightness"
+	ColorModeColorTemp  = "color_temp"
+	ColorModeHS         = "hs"
+	ColorModeXY         = "xy"
+	ColorModeRGB        = "rgb"
+	ColorModeRGBW       = "rgbw"
+	ColorModeRGBWW      = "rgbww"
+	ColorModeWhite      = "white"
+)
+
+// Flash lengths for WithFlash.
+const (
+	FlashShort = "short"
+	FlashLong  = "long"
+)
+
+// colorModes are the color modes in which a light can show any color. Home
+// Assistant converts between color spaces, so a light in any of these modes
+// accepts a color in any format.
+var colorModes = []string{ColorModeHS, ColorModeXY, ColorModeRGB, ColorModeRGBW, ColorModeRGBWW}
+
+// The following options can be passed to Light.TurnOn and LightGroup.TurnOn,
+// e.g.:
+//
+//	light.TurnOn(hal.WithKelvin(2700), hal.WithBrightnessPct(40), hal.WithTransition(2*time.Second))
+
+// WithKelvin sets the color temperature, in Kelvin.
+func WithKelvin(kelvin int) map[string]any {
+	return map[string]any{"color_temp_kelvin": kelvin}
+}
+
+// WithRGB sets the color as red, green and blue values (0-255).
+func WithRGB(r, g, b uint8) map[string]any {
+	return map[string]any{"rgb_color": []int{int(r), int(g), int(b)}}
+}
+
+// WithHS sets the color as hue (0-360) and saturation (0-100).
+func WithHS(hue, saturation float64) map[string]any {
+	return map[string]any{"hs_color": []float64{hue, saturation}}
+}
+
+// WithXY sets the color as CIE 1931 x and y coordinates (0-1).
+func WithXY(x, y float64) map[string]any {
+	return map[string]any{"xy_color": []float64{x, y}}
+}
+
+// WithBrightnessPct sets the brightness as a percentage (0-100).
+func WithBrightnessPct(pct int) map[string]any {
+	return map[string]any{"brightness_pct": pct}
+}
+
+// WithTransition fades the light to its new state over the given duration.
+func WithTransition(transition time.Duration) map[string]any {
+	return map[string]any{"transition": transition.Seconds()}
+}
+
+// WithEffect sets the light effect. It must be one of Light.Effects.
+func WithEffect(effect string) map[string]any {
+	return map[string]any{"effect": effect}
+}
+
+// WithFlash flashes the light, FlashShort or FlashLong.
+func WithFlash(flash string) map[string]any {
+	return map[string]any{"flash": flash}
+}
+
+// validateTurnOn checks light.turn_on service data against the capabilities
+// the light advertises. Capabilities that the light does not report (e.g.
+// before its state is known) are not checked.
+func (l *Light) validateTurnOn(data map[string]any) error {
+	supportedModes := l.SupportedColorModes()
+
+	supports := func(modes ...string) bool {
+		for _, mode := range modes {
+			if slices.Contains(supportedModes, mode) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for key, value := range data {
+		switch key {
+		case "color_temp_kelvin", "kelvin", "color_temp":
+			// Home Assistant converts a color temperature to a color for
+			// lights that only support colors.
+			if len(supportedModes) > 0 && !supports(ColorModeColorTemp) && !supports(colorModes...) {
+				return l.unsupported(key, supportedModes)
+			}
+
+			if key != "color_temp_kelvin" {
+				continue
+			}
+
+			kelvin, ok := getFloat(value)
+			if !ok {
+				continue
+			}
+
+			// Only the limits the light reports are checked.
+			minKelvin, minOK := l.MinColorTempKelvin()
+			maxKelvin, maxOK := l.MaxColorTempKelvin()
+
+			if minOK && kelvin < float64(minKelvin) {
+				return fmt.Errorf("%w: %s does not support %vK (minimum %dK)", ErrValueOutOfRange, l.GetID(), kelvin, minKelvin)
+			}
+
+			if maxOK && kelvin > float64(maxKelvin) {
+				return fmt.Errorf("%w: %s does not support %vK (maximum %dK)", ErrValueOutOfRange, l.GetID(), kelvin, maxKelvin)
+			}
+
+		case "rgb_color", "hs_color", "xy_color", "rgbw_color", "rgbww_color", "color_name":
+			if len(supportedModes) > 0 && !supports(colorModes...) {
+				return l.unsupported(key, supportedModes)
+			}
+
+		case "brightness", "brightness_pct", "brightness_step", "brightness_step_pct":
+			if len(supportedModes) > 0 && !slices.ContainsFunc(supportedModes, func(mode string) bool { return mode != ColorModeOnOff }) {
+				return l.unsupported(key, supportedModes)
+			}
+
+			if key == "brightness_pct" {
+				if pct, ok := getFloat(value); ok && (pct < 0 || pct > 100) {
+					return fmt.Errorf("%w: brightness_pct %v", ErrValueOutOfRange, pct)
+				}
+			}
+
+		case "effect":
+			effects := l.Effects()
+			effect := getString(value)
+
+			if _, reported := l.GetState().Attributes["effect_list"]; reported && !slices.Contains(effects, effect) {
+				return fmt.Errorf("%w: effect %q not in %v", ErrUnsupportedValue, effect, effects)
+			}
+
+		case "flash":
+			if flash := getString(value); flash != FlashShort && flash != FlashLong {
+				return fmt.Errorf("%w: flash %q", ErrUnsupportedValue, flash)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *Light) unsupported(key string, supportedModes []string) error {
+	return fmt.Errorf("%w: %s does not support %s (supported color modes: %v)", ErrFeatureNotSupported, l.GetID(), key, supportedModes)
+}
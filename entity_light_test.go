@@ -1,10 +1,17 @@
 package hal_test
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/hassws"
 	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
 	"gotest.tools/v3/assert"
 )
 
@@ -144,19 +151,19 @@ func TestLightGroup_GetBrightness(t *testing.T) {
 		assert.Equal(t, lg.GetBrightness(), float64(0))
 	})
 
-	t.Run("returns brightness of first light", func(t *testing.T) {
+	t.Run("returns mean brightness of lights that are on", func(t *testing.T) {
 		t.Parallel()
 		light1 := hal.NewLight("light.1")
 		light2 := hal.NewLight("light.2")
+		light3 := hal.NewLight("light.3")
 
-		state1 := homeassistant.State{Attributes: map[string]any{"brightness": float64(100)}}
-		state2 := homeassistant.State{Attributes: map[string]any{"brightness": float64(200)}}
+		light1.SetState(homeassistant.State{State: "on", Attributes: map[string]any{"brightness": float64(100)}})
+		light2.SetState(homeassistant.State{State: "on", Attributes: map[string]any{"brightness": float64(200)}})
+		light3.SetState(homeassistant.State{State: "off"})
 
-		light1.SetState(state1)
-		light2.SetState(state2)
-
-		lg := hal.LightGroup{light1, light2}
-		assert.Equal(t, lg.GetBrightness(), float64(100))
+		lg := hal.LightGroup{light1, light2, light3}
+		assert.Equal(t, lg.GetBrightness(), float64(150))
+		assert.Equal(t, lg.MaxBrightness(), float64(200))
 	})
 }
 
@@ -169,15 +176,38 @@ func TestLightGroup_GetState(t *testing.T) {
 		assert.DeepEqual(t, state, homeassistant.State{})
 	})
 
-	t.Run("returns state of first light", func(t *testing.T) {
+	t.Run("returns aggregate state", func(t *testing.T) {
 		t.Parallel()
 		light1 := hal.NewLight("light.1")
-		expectedState := homeassistant.State{State: "on", Attributes: map[string]any{"brightness": float64(255)}}
-		light1.SetState(expectedState)
+		light2 := hal.NewLight("light.2")
+		lg := hal.LightGroup{light1, light2}
 
-		lg := hal.LightGroup{light1}
-		state := lg.GetState()
-		assert.DeepEqual(t, state, expectedState)
+		light1.SetState(homeassistant.State{EntityID: "light.1", State: "off"})
+		light2.SetState(homeassistant.State{EntityID: "light.2", State: "unavailable"})
+		assert.Equal(t, lg.GetState().State, "off")
+
+		light1.SetState(homeassistant.State{EntityID: "light.1", State: "unavailable"})
+		assert.Equal(t, lg.GetState().State, "unavailable")
+
+		light1.SetState(homeassistant.State{
+			EntityID:   "light.1",
+			State:      "on",
+			Attributes: map[string]any{"brightness": float64(255), "color_mode": "color_temp"},
+		})
+		light2.SetState(homeassistant.State{
+			EntityID:   "light.2",
+			State:      "on",
+			Attributes: map[string]any{"brightness": float64(55), "color_mode": "rgb"},
+		})
+
+		assert.DeepEqual(t, lg.GetState(), homeassistant.State{
+			State: "on",
+			Attributes: map[string]any{
+				"entity_id":  []string{"light.1", "light.2"},
+				"brightness": float64(155),
+				"color_mode": hal.ColorModeMixed,
+			},
+		})
 	})
 }
 
@@ -291,3 +321,185 @@ func TestLightGroup_BindConnection(t *testing.T) {
 		assert.Equal(t, len(lg), 2)
 	})
 }
+
+func TestLight_Getters(t *testing.T) {
+	t.Parallel()
+
+	light := hal.NewLight("light.test")
+	light.SetState(homeassistant.State{
+		EntityID: "light.test",
+		State:    "on",
+		Attributes: map[string]any{
+			"color_mode":            "rgb",
+			"supported_color_modes": []any{"color_temp", "rgb"},
+			"rgb_color":             []any{float64(255), float64(128), float64(0)},
+			"effect_list":           []any{"colorloop", "candle"},
+			"min_color_temp_kelvin": float64(2000),
+			"max_color_temp_kelvin": float64(6500),
+		},
+	})
+
+	assert.Equal(t, light.ColorMode(), hal.ColorModeRGB)
+	assert.DeepEqual(t, light.SupportedColorModes(), []string{"color_temp", "rgb"})
+	assert.DeepEqual(t, light.Effects(), []string{"colorloop", "candle"})
+
+	r, g, b, ok := light.RGB()
+	assert.Assert(t, ok)
+	assert.Equal(t, [3]uint8{r, g, b}, [3]uint8{255, 128, 0})
+
+	_, ok = light.ColorTempKelvin()
+	assert.Assert(t, !ok)
+
+	minKelvin, _ := light.MinColorTempKelvin()
+	assert.Equal(t, minKelvin, 2000)
+}
+
+func TestLight_TurnOnValidation(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	light := hal.NewLight("light.white_only")
+	conn.RegisterEntities(light)
+
+	light.SetState(homeassistant.State{
+		EntityID: "light.white_only",
+		State:    "off",
+		Attributes: map[string]any{
+			"supported_color_modes": []any{"color_temp"},
+			"min_color_temp_kelvin": float64(2700),
+			"max_color_temp_kelvin": float64(6500),
+			"effect_list":           []any{"candle"},
+		},
+	})
+
+	ctx := context.Background()
+
+	assert.ErrorIs(t, light.TurnOnContext(ctx, hal.WithRGB(255, 0, 0)), hal.ErrFeatureNotSupported)
+	assert.ErrorIs(t, light.TurnOn(hal.WithHS(120, 100)), hal.ErrFeatureNotSupported)
+	assert.ErrorIs(t, light.TurnOn(hal.WithKelvin(2000)), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, light.TurnOn(hal.WithBrightnessPct(120)), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, light.TurnOn(hal.WithEffect("colorloop")), hal.ErrUnsupportedValue)
+	assert.ErrorIs(t, light.TurnOn(hal.WithFlash("forever")), hal.ErrUnsupportedValue)
+
+	assert.NilError(t, light.TurnOnContext(ctx, hal.WithKelvin(3000), hal.WithBrightnessPct(40), hal.WithTransition(time.Second)))
+	testutil.WaitFor(t, "verify color temperature set", func() bool {
+		kelvin, _ := light.ColorTempKelvin()
+
+		return light.IsOn() && kelvin == 3000
+	}, func() {
+		spew.Dump(light.GetState())
+	})
+}
+
+func TestLight_ColorTempOnColorLight(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	rgb := hal.NewLight("light.rgb_only")
+	dimmable := hal.NewLight("light.dimmable")
+	conn.RegisterEntities(rgb, dimmable)
+
+	rgb.SetState(homeassistant.State{
+		EntityID:   "light.rgb_only",
+		State:      "off",
+		Attributes: map[string]any{"supported_color_modes": []any{"rgb"}},
+	})
+
+	dimmable.SetState(homeassistant.State{
+		EntityID:   "light.dimmable",
+		State:      "off",
+		Attributes: map[string]any{"supported_color_modes": []any{"brightness"}},
+	})
+
+	// Home Assistant converts the color temperature to a color, and there is
+	// no range to check it against.
+	assert.NilError(t, rgb.TurnOn(hal.WithKelvin(2700)))
+	assert.ErrorIs(t, dimmable.TurnOn(hal.WithKelvin(2700)), hal.ErrFeatureNotSupported)
+}
+
+func TestLightGroup_AnyOnAllOn(t *testing.T) {
+	t.Parallel()
+
+	light1 := hal.NewLight("light.1")
+	light2 := hal.NewLight("light.2")
+	lg := hal.LightGroup{light1, light2}
+
+	light1.SetState(homeassistant.State{State: "on"})
+	light2.SetState(homeassistant.State{State: "off"})
+
+	assert.Assert(t, lg.AnyOn())
+	assert.Assert(t, !lg.AllOn())
+	assert.Assert(t, !lg.IsOff())
+}
+
+// countLightServiceCalls returns the number of light service calls received
+// by the server.
+func countLightServiceCalls(server *hassws.Server) int {
+	count := 0
+
+	for _, msg := range server.MessagesReceived() {
+		if strings.Contains(string(msg), `"domain":"light"`) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestLightGroup_BatchedServiceCalls(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	lights := make(hal.LightGroup, 4)
+	for i := range lights {
+		lights[i] = hal.NewLight(fmt.Sprintf("light.%d", i))
+	}
+
+	for _, light := range lights {
+		conn.RegisterEntities(light)
+	}
+
+	assert.NilError(t, lights.TurnOnContext(context.Background(), hal.WithBrightnessPct(50)))
+	testutil.WaitFor(t, "verify lights turned on", lights.AllOn, func() {
+		spew.Dump(lights.GetState())
+	})
+	assert.Equal(t, countLightServiceCalls(server), 1)
+
+	// Lights given the same attributes share a call.
+	assert.NilError(t, lights.TurnOnEachContext(context.Background(), func(l hal.LightInterface) map[string]any {
+		if l.GetID() == "light.0" {
+			return hal.WithBrightnessPct(100)
+		}
+
+		return hal.WithBrightnessPct(20)
+	}))
+	assert.Equal(t, countLightServiceCalls(server), 3)
+
+	assert.NilError(t, lights.TurnOff())
+	testutil.WaitFor(t, "verify lights turned off", lights.IsOff, func() {
+		spew.Dump(lights.GetState())
+	})
+	assert.Equal(t, countLightServiceCalls(server), 4)
+}
+
+func TestLightGroup_IsOffWhenUnavailable(t *testing.T) {
+	t.Parallel()
+
+	light1 := hal.NewLight("light.1")
+	light2 := hal.NewLight("light.2")
+	lg := hal.LightGroup{light1, light2}
+
+	light1.SetState(homeassistant.State{State: "off"})
+	light2.SetState(homeassistant.State{State: "unavailable"})
+
+	assert.Assert(t, !lg.IsOn())
+	assert.Assert(t, !lg.IsOff())
+	assert.Assert(t, !light2.IsOn())
+	assert.Assert(t, !light2.IsOff())
+}
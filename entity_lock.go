@@ -0,0 +1,102 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// LockFeatureOpen is the supported_features flag for locks that can open (i.e.
+// unlatch) the door.
+const LockFeatureOpen = 1
+
+// Lock states.
+const (
+	LockStateLocked    = "locked"
+	LockStateUnlocked  = "unlocked"
+	LockStateLocking   = "locking"
+	LockStateUnlocking = "unlocking"
+	LockStateJammed    = "jammed"
+	LockStateOpen      = "open"
+	LockStateOpening   = "opening"
+)
+
+// Lock is a door lock.
+type Lock struct {
+	*Entity
+}
+
+func NewLock(id string) *Lock {
+	return &Lock{Entity: NewEntity(id)}
+}
+
+func (l *Lock) IsLocked() bool {
+	return l.GetState().State == LockStateLocked
+}
+
+func (l *Lock) IsUnlocked() bool {
+	return l.GetState().State == LockStateUnlocked
+}
+
+func (l *Lock) IsLocking() bool {
+	return l.GetState().State == LockStateLocking
+}
+
+func (l *Lock) IsUnlocking() bool {
+	return l.GetState().State == LockStateUnlocking
+}
+
+// IsJammed returns true if the lock failed to lock or unlock.
+func (l *Lock) IsJammed() bool {
+	return l.GetState().State == LockStateJammed
+}
+
+func (l *Lock) IsOpen() bool {
+	return l.GetState().State == LockStateOpen
+}
+
+// Lock locks the lock. An error is returned if Home Assistant reports that the
+// service call failed, so that callers can retry or escalate.
+func (l *Lock) Lock(ctx context.Context) error {
+	logger.InfoContext(ctx, "Locking", "lock", l.GetID())
+
+	return l.call(ctx, "lock", "")
+}
+
+// Unlock unlocks the lock. The code may be empty for locks that do not require
+// one.
+func (l *Lock) Unlock(ctx context.Context, code string) error {
+	logger.InfoContext(ctx, "Unlocking", "lock", l.GetID())
+
+	return l.call(ctx, "unlock", code)
+}
+
+// Open unlatches the door, for locks that support it.
+func (l *Lock) Open(ctx context.Context) error {
+	if !l.supportsFeature(LockFeatureOpen) {
+		err := fmt.Errorf("%w: lock.open", ErrFeatureNotSupported)
+		logger.ErrorContext(ctx, "Lock does not support opening", "lock", l.GetID(), "error", err)
+
+		return err
+	}
+
+	logger.InfoContext(ctx, "Opening lock", "lock", l.GetID())
+
+	return l.call(ctx, "open", "")
+}
+
+func (l *Lock) call(ctx context.Context, service string, code string) error {
+	var data map[string]any
+	if code != "" {
+		data = map[string]any{"code": code}
+	}
+
+	if err := l.callService("lock", service, data); err != nil {
+		logger.ErrorContext(ctx, "Error calling lock service", "lock", l.GetID(), "service", service, "error", err)
+
+		return err
+	}
+
+	return nil
+}
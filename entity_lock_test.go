@@ -0,0 +1,62 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/hassws"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestLock_States(t *testing.T) {
+	t.Parallel()
+
+	lock := hal.NewLock("lock.front_door")
+
+	lock.SetState(homeassistant.State{State: "jammed"})
+	assert.Assert(t, lock.IsJammed())
+	assert.Assert(t, !lock.IsLocked())
+	assert.Assert(t, !lock.IsUnlocked())
+
+	lock.SetState(homeassistant.State{State: "locking"})
+	assert.Assert(t, lock.IsLocking())
+
+	assert.ErrorIs(t, lock.Open(context.Background()), hal.ErrFeatureNotSupported)
+	assert.ErrorIs(t, lock.Lock(context.Background()), hal.ErrEntityNotRegistered)
+}
+
+func TestLock_ServiceCalls(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	lock := hal.NewLock("lock.front_door")
+	conn.RegisterEntities(lock)
+
+	ctx := context.Background()
+
+	assert.NilError(t, lock.Unlock(ctx, "1234"))
+	testutil.WaitFor(t, "verify lock unlocked", lock.IsUnlocked, func() {
+		spew.Dump(lock.GetState())
+	})
+
+	// A failed service call is returned to the caller.
+	server.SetCallServiceError("lock.lock", "home_assistant_error", "Lock is jammed")
+
+	err := lock.Lock(ctx)
+	assert.ErrorIs(t, err, hassws.ErrCallServiceFailed)
+	assert.ErrorContains(t, err, "Lock is jammed")
+	assert.Assert(t, lock.IsUnlocked())
+
+	server.SetCallServiceError("lock.lock", "", "")
+
+	assert.NilError(t, lock.Lock(ctx))
+	testutil.WaitFor(t, "verify lock locked", lock.IsLocked, func() {
+		spew.Dump(lock.GetState())
+	})
+}
@@ -0,0 +1,184 @@
+package hal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// Media player states.
+const (
+	MediaPlayerStateOff       = "off"
+	MediaPlayerStateOn        = "on"
+	MediaPlayerStateIdle      = "idle"
+	MediaPlayerStatePlaying   = "playing"
+	MediaPlayerStatePaused    = "paused"
+	MediaPlayerStateBuffering = "buffering"
+)
+
+// MediaPlayer is a speaker, TV or other device that plays media.
+type MediaPlayer struct {
+	*Entity
+}
+
+func NewMediaPlayer(id string) *MediaPlayer {
+	return &MediaPlayer{Entity: NewEntity(id)}
+}
+
+func (m *MediaPlayer) IsPlaying() bool {
+	return m.GetState().State == MediaPlayerStatePlaying
+}
+
+func (m *MediaPlayer) IsPaused() bool {
+	return m.GetState().State == MediaPlayerStatePaused
+}
+
+func (m *MediaPlayer) IsIdle() bool {
+	return m.GetState().State == MediaPlayerStateIdle
+}
+
+func (m *MediaPlayer) IsOff() bool {
+	return m.GetState().State == MediaPlayerStateOff
+}
+
+// VolumeLevel returns the volume, between 0 and 1. The second return value is
+// false if the player does not report its volume (e.g. when it is off).
+func (m *MediaPlayer) VolumeLevel() (float64, bool) {
+	return getFloat(m.GetState().Attributes["volume_level"])
+}
+
+func (m *MediaPlayer) IsVolumeMuted() bool {
+	muted, _ := m.GetState().Attributes["is_volume_muted"].(bool)
+
+	return muted
+}
+
+func (m *MediaPlayer) Source() string {
+	return getString(m.GetState().Attributes["source"])
+}
+
+func (m *MediaPlayer) SourceList() []string {
+	return getStringOrStringSlice(m.GetState().Attributes["source_list"])
+}
+
+func (m *MediaPlayer) MediaTitle() string {
+	return getString(m.GetState().Attributes["media_title"])
+}
+
+func (m *MediaPlayer) MediaArtist() string {
+	return getString(m.GetState().Attributes["media_artist"])
+}
+
+func (m *MediaPlayer) MediaAlbumName() string {
+	return getString(m.GetState().Attributes["media_album_name"])
+}
+
+func (m *MediaPlayer) MediaContentID() string {
+	return getString(m.GetState().Attributes["media_content_id"])
+}
+
+func (m *MediaPlayer) MediaContentType() string {
+	return getString(m.GetState().Attributes["media_content_type"])
+}
+
+func (m *MediaPlayer) Play(ctx context.Context) error {
+	logger.InfoContext(ctx, "Playing media", "media_player", m.GetID())
+
+	return m.call(ctx, "media_play", nil)
+}
+
+func (m *MediaPlayer) Pause(ctx context.Context) error {
+	logger.InfoContext(ctx, "Pausing media", "media_player", m.GetID())
+
+	return m.call(ctx, "media_pause", nil)
+}
+
+func (m *MediaPlayer) Stop(ctx context.Context) error {
+	logger.InfoContext(ctx, "Stopping media", "media_player", m.GetID())
+
+	return m.call(ctx, "media_stop", nil)
+}
+
+// SetVolume sets the volume, between 0 and 1.
+func (m *MediaPlayer) SetVolume(ctx context.Context, level float64) error {
+	if level < 0 || level > 1 {
+		return fmt.Errorf("%w: volume level %v", ErrValueOutOfRange, level)
+	}
+
+	logger.InfoContext(ctx, "Setting volume", "media_player", m.GetID(), "level", level)
+
+	return m.call(ctx, "volume_set", map[string]any{"volume_level": level})
+}
+
+// SelectSource switches to the given input source. The source must be one of
+// SourceList.
+func (m *MediaPlayer) SelectSource(ctx context.Context, source string) error {
+	if !slices.Contains(m.SourceList(), source) {
+		return fmt.Errorf("%w: source %q not in %v", ErrUnsupportedValue, source, m.SourceList())
+	}
+
+	logger.InfoContext(ctx, "Selecting source", "media_player", m.GetID(), "source", source)
+
+	return m.call(ctx, "select_source", map[string]any{"source": source})
+}
+
+// PlayMedia plays the given media. When announce is true the media is played
+// as an announcement: players that support it pause the current media and
+// resume it afterwards.
+func (m *MediaPlayer) PlayMedia(ctx context.Context, contentID, contentType string, announce bool) error {
+	logger.InfoContext(ctx, "Playing media", "media_player", m.GetID(), "content_id", contentID, "announce", announce)
+
+	data := map[string]any{
+		"media_content_id":   contentID,
+		"media_content_type": contentType,
+	}
+
+	if announce {
+		data["announce"] = true
+	}
+
+	return m.call(ctx, "play_media", data)
+}
+
+// Announce plays the given media as an announcement at the given volume. It
+// returns a function that restores the volume the player had beforehand; call
+// it once the announcement has finished. If the media fails to play, the
+// volume is restored before returning the error.
+func (m *MediaPlayer) Announce(ctx context.Context, contentID, contentType string, volume float64) (restore func(context.Context) error, err error) {
+	previousVolume, hadVolume := m.VolumeLevel()
+
+	restore = func(ctx context.Context) error {
+		if !hadVolume {
+			return nil
+		}
+
+		return m.SetVolume(ctx, previousVolume)
+	}
+
+	if err := m.SetVolume(ctx, volume); err != nil {
+		return nil, err
+	}
+
+	if err := m.PlayMedia(ctx, contentID, contentType, true); err != nil {
+		if restoreErr := restore(ctx); restoreErr != nil {
+			return nil, errors.Join(err, restoreErr)
+		}
+
+		return nil, err
+	}
+
+	return restore, nil
+}
+
+func (m *MediaPlayer) call(ctx context.Context, service string, data map[string]any) error {
+	if err := m.callService("media_player", service, data); err != nil {
+		logger.ErrorContext(ctx, "Error calling media player service", "media_player", m.GetID(), "service", service, "error", err)
+
+		return err
+	}
+
+	return nil
+}
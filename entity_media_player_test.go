@@ -0,0 +1,147 @@
+package hal_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/hassws"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestMediaPlayer_Attributes(t *testing.T) {
+	t.Parallel()
+
+	player := hal.NewMediaPlayer("media_player.kitchen")
+	player.SetState(homeassistant.State{
+		State: "playing",
+		Attributes: map[string]any{
+			"volume_level":    0.35,
+			"is_volume_muted": false,
+			"source":          "Spotify",
+			"source_list":     []any{"Spotify", "TV"},
+			"media_title":     "Song",
+			"media_artist":    "Artist",
+		},
+	})
+
+	assert.Assert(t, player.IsPlaying())
+	assert.Assert(t, !player.IsPaused())
+	assert.Assert(t, !player.IsVolumeMuted())
+	assert.Equal(t, player.Source(), "Spotify")
+	assert.Equal(t, player.MediaTitle(), "Song")
+	assert.Equal(t, player.MediaArtist(), "Artist")
+
+	volume, ok := player.VolumeLevel()
+	assert.Assert(t, ok)
+	assert.Equal(t, volume, 0.35)
+
+	ctx := context.Background()
+	assert.ErrorIs(t, player.SetVolume(ctx, 1.5), hal.ErrValueOutOfRange)
+	assert.ErrorIs(t, player.SelectSource(ctx, "Radio"), hal.ErrUnsupportedValue)
+	assert.ErrorIs(t, player.SelectSource(ctx, "TV"), hal.ErrEntityNotRegistered)
+}
+
+func TestMediaPlayer_AnnounceRestoresVolume(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	player := hal.NewMediaPlayer("media_player.kitchen")
+	conn.RegisterEntities(player)
+
+	server.SendEvent(homeassistant.Event{
+		EventType: "state_changed",
+		EventData: homeassistant.EventData{
+			EntityID: player.GetID(),
+			NewState: &homeassistant.State{
+				EntityID:   player.GetID(),
+				State:      "idle",
+				Attributes: map[string]any{"volume_level": 0.2},
+			},
+		},
+	})
+
+	testutil.WaitFor(t, "verify initial state", player.IsIdle, func() {
+		spew.Dump(player.GetState())
+	})
+
+	ctx := context.Background()
+
+	restore, err := player.Announce(ctx, "media-source://tts?message=Dinner", "music", 0.6)
+	assert.NilError(t, err)
+
+	testutil.WaitFor(t, "verify announcement playing at volume", func() bool {
+		volume, _ := player.VolumeLevel()
+
+		return player.IsPlaying() && volume == 0.6
+	}, func() {
+		spew.Dump(player.GetState())
+	})
+
+	assert.NilError(t, restore(ctx))
+
+	testutil.WaitFor(t, "verify volume restored", func() bool {
+		volume, _ := player.VolumeLevel()
+
+		return volume == 0.2
+	}, func() {
+		spew.Dump(player.GetState())
+	})
+}
+
+func TestMediaPlayer_AnnounceRestoresVolumeOnError(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	player := hal.NewMediaPlayer("media_player.kitchen")
+	conn.RegisterEntities(player)
+
+	server.SendEvent(homeassistant.Event{
+		EventType: "state_changed",
+		EventData: homeassistant.EventData{
+			EntityID: player.GetID(),
+			NewState: &homeassistant.State{
+				EntityID:   player.GetID(),
+				State:      "idle",
+				Attributes: map[string]any{"volume_level": 0.2},
+			},
+		},
+	})
+
+	testutil.WaitFor(t, "verify initial state", player.IsIdle, func() {
+		spew.Dump(player.GetState())
+	})
+
+	server.SetCallServiceError("media_player.play_media", "home_assistant_error", "Speaker offline")
+
+	restore, err := player.Announce(context.Background(), "media-source://tts?message=Dinner", "music", 0.6)
+	assert.ErrorContains(t, err, "Speaker offline")
+	assert.Assert(t, restore == nil)
+
+	testutil.WaitFor(t, "verify volume restored", func() bool {
+		volume, _ := player.VolumeLevel()
+
+		return volume == 0.2
+	}, func() {
+		spew.Dump(player.GetState())
+	})
+
+	var volumes []any
+
+	for _, msg := range server.MessagesReceived() {
+		var req hassws.CallServiceRequest
+		if json.Unmarshal(msg, &req) == nil && req.Service == "volume_set" {
+			volumes = append(volumes, req.Data["volume_level"])
+		}
+	}
+
+	assert.DeepEqual(t, volumes, []any{0.6, 0.2})
+}
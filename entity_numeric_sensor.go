@@ -0,0 +1,60 @@
+package hal
+
+import (
+	"strconv"
+
+	"github.com/dansimau/hal/homeassistant"
+)
+
+// NumericSensor is a sensor with a numeric state, such as temperature,
+// humidity, power or CO2.
+type NumericSensor struct {
+	*Entity
+}
+
+func NewNumericSensor(id string) *NumericSensor {
+	return &NumericSensor{Entity: NewEntity(id)}
+}
+
+// Value returns the sensor reading. The second return value is false if the
+// sensor is unavailable, its state is unknown or it is not a number.
+func (s *NumericSensor) Value() (float64, bool) {
+	state := s.GetState().State
+	if state == homeassistant.StateUnavailable || state == homeassistant.StateUnknown {
+		return 0, false
+	}
+
+	v, err := strconv.ParseFloat(state, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
+
+// ValueIn returns the sensor reading converted to the given unit. Conversion is
+// supported between temperature units and between power units. The second
+// return value is false if there is no reading or it cannot be converted.
+func (s *NumericSensor) ValueIn(unit string) (float64, bool) {
+	v, ok := s.Value()
+	if !ok {
+		return 0, false
+	}
+
+	return convertUnit(v, s.Unit(), unit)
+}
+
+// Unit returns the unit_of_measurement attribute, e.g. "°C" or "W".
+func (s *NumericSensor) Unit() string {
+	return getString(s.GetState().Attributes["unit_of_measurement"])
+}
+
+// DeviceClass returns the device_class attribute, e.g. "temperature".
+func (s *NumericSensor) DeviceClass() string {
+	return getString(s.GetState().Attributes["device_class"])
+}
+
+// StateClass returns the state_class attribute, e.g. "measurement".
+func (s *NumericSensor) StateClass() string {
+	return getString(s.GetState().Attributes["state_class"])
+}
@@ -0,0 +1,96 @@
+package hal_test
+
+import (
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"gotest.tools/v3/assert"
+)
+
+func TestNumericSensor_Value(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		state  string
+		want   float64
+		wantOK bool
+	}{
+		{state: "21.5", want: 21.5, wantOK: true},
+		{state: "0", want: 0, wantOK: true},
+		{state: "-3", want: -3, wantOK: true},
+		{state: "unavailable", wantOK: false},
+		{state: "unknown", wantOK: false},
+		{state: "", wantOK: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.state, func(t *testing.T) {
+			t.Parallel()
+
+			sensor := hal.NewNumericSensor("sensor.test")
+			sensor.SetState(homeassistant.State{State: tc.state})
+
+			got, ok := sensor.Value()
+			assert.Equal(t, ok, tc.wantOK)
+			assert.Equal(t, got, tc.want)
+		})
+	}
+}
+
+func TestNumericSensor_Attributes(t *testing.T) {
+	t.Parallel()
+
+	sensor := hal.NewNumericSensor("sensor.temperature")
+	sensor.SetState(homeassistant.State{
+		State: "20",
+		Attributes: map[string]any{
+			"unit_of_measurement": "°C",
+			"device_class":        "temperature",
+			"state_class":         "measurement",
+		},
+	})
+
+	assert.Equal(t, sensor.Unit(), hal.UnitCelsius)
+	assert.Equal(t, sensor.DeviceClass(), "temperature")
+	assert.Equal(t, sensor.StateClass(), "measurement")
+}
+
+func TestNumericSensor_ValueIn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		state  string
+		unit   string
+		to     string
+		want   float64
+		wantOK bool
+	}{
+		{name: "same unit", state: "20", unit: hal.UnitCelsius, to: hal.UnitCelsius, want: 20, wantOK: true},
+		{name: "celsius to fahrenheit", state: "20", unit: hal.UnitCelsius, to: hal.UnitFahrenheit, want: 68, wantOK: true},
+		{name: "fahrenheit to celsius", state: "212", unit: hal.UnitFahrenheit, to: hal.UnitCelsius, want: 100, wantOK: true},
+		{name: "kelvin to celsius", state: "273.15", unit: hal.UnitKelvin, to: hal.UnitCelsius, want: 0, wantOK: true},
+		{name: "kilowatts to watts", state: "1.5", unit: hal.UnitKilowatt, to: hal.UnitWatt, want: 1500, wantOK: true},
+		{name: "watts to kilowatts", state: "250", unit: hal.UnitWatt, to: hal.UnitKilowatt, want: 0.25, wantOK: true},
+		{name: "incompatible units", state: "20", unit: hal.UnitCelsius, to: hal.UnitWatt, wantOK: false},
+		{name: "unknown unit", state: "400", unit: "ppm", to: hal.UnitWatt, wantOK: false},
+		{name: "unavailable", state: "unavailable", unit: hal.UnitWatt, to: hal.UnitWatt, wantOK: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			sensor := hal.NewNumericSensor("sensor.test")
+			sensor.SetState(homeassistant.State{
+				State:      tc.state,
+				Attributes: map[string]any{"unit_of_measurement": tc.unit},
+			})
+
+			got, ok := sensor.ValueIn(tc.to)
+			assert.Equal(t, ok, tc.wantOK)
+			assert.Assert(t, got-tc.want < 1e-9 && tc.want-got < 1e-9, "got %v, want %v", got, tc.want)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package hal
+
+// ZoneHome is the state of a person or device tracker that is at home.
+const ZoneHome = "home"
+
+// ZoneNotHome is the state of a person or device tracker that is not in any
+// zone.
+const ZoneNotHome = "not_home"
+
+// presence implements the zone and location helpers shared by Person and
+// DeviceTracker.
+type presence struct {
+	*Entity
+}
+
+// Zone returns the zone the entity is in: "home", "not_home" or the name of
+// another zone.
+func (p *presence) Zone() string {
+	return p.GetState().State
+}
+
+func (p *presence) IsHome() bool {
+	return p.GetState().State == ZoneHome
+}
+
+// Coordinates returns the GPS coordinates of the entity. The last return value
+// is false if the entity does not report a location.
+func (p *presence) Coordinates() (latitude, longitude float64, ok bool) {
+	attributes := p.GetState().Attributes
+
+	latitude, latOK := getFloat(attributes["latitude"])
+	longitude, lngOK := getFloat(attributes["longitude"])
+
+	return latitude, longitude, latOK && lngOK
+}
+
+// GPSAccuracy returns the accuracy radius of the location in meters.
+func (p *presence) GPSAccuracy() (float64, bool) {
+	return getFloat(p.GetState().Attributes["gps_accuracy"])
+}
+
+// DistanceFromHome returns the distance in meters between the entity and the
+// location configured in Config.Location. The second return value is false if
+// the entity has no location, is not registered or no home location is
+// configured.
+func (p *presence) DistanceFromHome() (float64, bool) {
+	latitude, longitude, ok := p.Coordinates()
+	if !ok || p.connection == nil {
+		return 0, false
+	}
+
+	home := p.connection.config.Location
+	if home == (LocationConfig{}) {
+		return 0, false
+	}
+
+	return distanceMeters(home.Latitude, home.Longitude, latitude, longitude), true
+}
+
+// IsWithin returns true if the entity is within the given distance in meters of
+// home, as reported by DistanceFromHome.
+func (p *presence) IsWithin(meters float64) bool {
+	distance, ok := p.DistanceFromHome()
+
+	return ok && distance <= meters
+}
+
+// Person is a person tracked by Home Assistant, combining one or more device
+// trackers.
+type Person struct {
+	presence
+}
+
+func NewPerson(id string) *Person {
+	return &Person{presence: presence{Entity: NewEntity(id)}}
+}
+
+// UserID returns the ID of the Home Assistant user linked to the person.
+func (p *Person) UserID() string {
+	return getString(p.GetState().Attributes["user_id"])
+}
+
+// DeviceTracker is a phone, router integration or other device reporting a
+// location.
+type DeviceTracker struct {
+	presence
+}
+
+func NewDeviceTracker(id string) *DeviceTracker {
+	return &DeviceTracker{presence: presence{Entity: NewEntity(id)}}
+}
+
+// SourceType returns how the tracker determines its location, e.g. "gps" or
+// "router".
+func (d *DeviceTracker) SourceType() string {
+	return getString(d.GetState().Attributes["source_type"])
+}
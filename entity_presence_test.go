@@ -0,0 +1,103 @@
+package hal_test
+
+import (
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"gotest.tools/v3/assert"
+)
+
+func TestPerson_Zone(t *testing.T) {
+	t.Parallel()
+
+	person := hal.NewPerson("person.alex")
+
+	person.SetState(homeassistant.State{State: "home"})
+	assert.Assert(t, person.IsHome())
+	assert.Equal(t, person.Zone(), hal.ZoneHome)
+
+	person.SetState(homeassistant.State{State: "Work"})
+	assert.Assert(t, !person.IsHome())
+	assert.Equal(t, person.Zone(), "Work")
+
+	person.SetState(homeassistant.State{State: "unavailable"})
+	assert.Assert(t, !person.IsHome())
+}
+
+func TestDeviceTracker_Coordinates(t *testing.T) {
+	t.Parallel()
+
+	tracker := hal.NewDeviceTracker("device_tracker.phone")
+
+	_, _, ok := tracker.Coordinates()
+	assert.Assert(t, !ok)
+
+	tracker.SetState(homeassistant.State{
+		State: "not_home",
+		Attributes: map[string]any{
+			"latitude":     51.5,
+			"longitude":    -0.12,
+			"gps_accuracy": float64(20),
+			"source_type":  "gps",
+		},
+	})
+
+	latitude, longitude, ok := tracker.Coordinates()
+	assert.Assert(t, ok)
+	assert.Equal(t, latitude, 51.5)
+	assert.Equal(t, longitude, -0.12)
+
+	accuracy, ok := tracker.GPSAccuracy()
+	assert.Assert(t, ok)
+	assert.Equal(t, accuracy, float64(20))
+	assert.Equal(t, tracker.SourceType(), "gps")
+
+	// Not registered, so there is no home location to measure against.
+	_, ok = tracker.DistanceFromHome()
+	assert.Assert(t, !ok)
+}
+
+func TestPerson_DistanceFromHome(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServerWithConfig(t, hal.Config{
+		Location: hal.LocationConfig{Latitude: 51.5074, Longitude: -0.1278},
+	})
+	defer cleanup()
+
+	person := hal.NewPerson("person.alex")
+	conn.RegisterEntities(person)
+
+	// Roughly 400m north of home.
+	person.SetState(homeassistant.State{
+		State:      "not_home",
+		Attributes: map[string]any{"latitude": 51.5110, "longitude": -0.1278},
+	})
+
+	distance, ok := person.DistanceFromHome()
+	assert.Assert(t, ok)
+	assert.Assert(t, distance > 390 && distance < 410, "distance %v", distance)
+	assert.Assert(t, person.IsWithin(500))
+	assert.Assert(t, !person.IsWithin(100))
+}
+
+func TestPerson_DistanceFromHomeWithoutLocation(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	person := hal.NewPerson("person.alex")
+	conn.RegisterEntities(person)
+
+	person.SetState(homeassistant.State{
+		State:      "not_home",
+		Attributes: map[string]any{"latitude": 0.001, "longitude": 0.001},
+	})
+
+	_, ok := person.DistanceFromHome()
+	assert.Assert(t, !ok)
+	assert.Assert(t, !person.IsWithin(500))
+}
@@ -0,0 +1,48 @@
+package hal
+
+import (
+	"context"
+	"time"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// Scene is a Home Assistant scene. Its state is the time it was last
+// activated.
+type Scene struct {
+	*Entity
+}
+
+func NewScene(id string) *Scene {
+	return &Scene{Entity: NewEntity(id)}
+}
+
+// LastActivated returns when the scene was last activated. The second return
+// value is false if it has not been activated since Home Assistant started.
+func (s *Scene) LastActivated() (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339Nano, s.GetState().State)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// Activate activates the scene. If transition is non-zero, lights that
+// support it fade to their new state over that duration.
+func (s *Scene) Activate(ctx context.Context, transition time.Duration) error {
+	logger.InfoContext(ctx, "Activating scene", "scene", s.GetID(), "transition", transition)
+
+	var data map[string]any
+	if transition > 0 {
+		data = map[string]any{"transition": transition.Seconds()}
+	}
+
+	if err := s.callService("scene", "turn_on", data); err != nil {
+		logger.ErrorContext(ctx, "Error activating scene", "scene", s.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestScene_Activate(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	scene := hal.NewScene("scene.movie_night")
+	assert.ErrorIs(t, scene.Activate(context.Background(), 0), hal.ErrEntityNotRegistered)
+
+	conn.RegisterEntities(scene)
+
+	_, activated := scene.LastActivated()
+	assert.Assert(t, !activated)
+
+	assert.NilError(t, scene.Activate(context.Background(), 2*time.Second))
+	testutil.WaitFor(t, "verify scene activated", func() bool {
+		_, activated := scene.LastActivated()
+
+		return activated
+	}, func() {
+		spew.Dump(scene.GetState())
+	})
+}
@@ -0,0 +1,105 @@
+package hal
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+)
+
+// Script is a Home Assistant script. Its state is "on" while it is running.
+type Script struct {
+	*Entity
+}
+
+func NewScript(id string) *Script {
+	return &Script{Entity: NewEntity(id)}
+}
+
+func (s *Script) IsRunning() bool {
+	return s.GetState().State == "on"
+}
+
+// Run starts the script with the given variables and returns without waiting
+// for it to finish. Variables may be nil.
+func (s *Script) Run(ctx context.Context, variables map[string]any) error {
+	logger.InfoContext(ctx, "Running script", "script", s.GetID())
+
+	var data map[string]any
+	if len(variables) > 0 {
+		data = map[string]any{"variables": variables}
+	}
+
+	if err := s.callService("script", "turn_on", data); err != nil {
+		logger.ErrorContext(ctx, "Error running script", "script", s.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// RunAndWait starts the script and blocks until it has finished, i.e. its
+// state has returned to "off", or until the context is done. It can be called
+// from an automation's Action, but no other automations run while it waits,
+// and the script's own state is not updated until the Action returns.
+func (s *Script) RunAndWait(ctx context.Context, variables map[string]any) error {
+	if s.connection == nil {
+		return ErrEntityNotRegistered
+	}
+
+	w := &scriptWaiter{
+		// If the script is already running, a new run may be queued or ignored
+		// depending on its mode, so no "on" state is delivered. Wait for the
+		// current run to finish instead.
+		sawOn: s.IsRunning(),
+		done:  make(chan struct{}),
+	}
+
+	// Observe states as they are received, since state changes are not
+	// applied while an automation is running.
+	remove := s.connection.observeState(s.GetID(), func(state homeassistant.State) {
+		w.observe(state.State)
+	})
+	defer remove()
+
+	if err := s.Run(ctx, variables); err != nil {
+		return err
+	}
+
+	select {
+	case <-w.done:
+		logger.InfoContext(ctx, "Script finished", "script", s.GetID())
+
+		return nil
+	case <-ctx.Done():
+		logger.ErrorContext(ctx, "Timed out waiting for script", "script", s.GetID(), "error", ctx.Err())
+
+		return ctx.Err()
+	}
+}
+
+// scriptWaiter tracks a single run of a script, which has finished once the
+// script has been seen running and then stopped.
+type scriptWaiter struct {
+	mutex sync.Mutex
+	sawOn bool
+	done  chan struct{}
+}
+
+func (w *scriptWaiter) observe(state string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	switch {
+	case state == "on":
+		w.sawOn = true
+	case state == "off" && w.sawOn:
+		select {
+		case <-w.done:
+		default:
+			close(w.done)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package hal_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestScript_RunAndWait(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	script := hal.NewScript("script.bedtime")
+	conn.RegisterEntities(script)
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- script.RunAndWait(context.Background(), map[string]any{"room": "bedroom"})
+	}()
+
+	testutil.WaitFor(t, "verify script running", script.IsRunning, func() {
+		spew.Dump(script.GetState())
+	})
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("RunAndWait returned before the script finished: %v", err)
+	default:
+	}
+
+	server.SendEvent(homeassistant.Event{
+		EventType: "state_changed",
+		EventData: homeassistant.EventData{
+			EntityID: script.GetID(),
+			NewState: &homeassistant.State{EntityID: script.GetID(), State: "off"},
+		},
+	})
+
+	select {
+	case err := <-errCh:
+		assert.NilError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("RunAndWait did not return after the script finished")
+	}
+}
+
+func TestScript_RunAndWaitFromAutomation(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	button := hal.NewInputBoolean("input_boolean.bedtime")
+	script := hal.NewScript("script.bedtime")
+	conn.RegisterEntities(button, script)
+
+	errCh := make(chan error, 1)
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("bedtime").
+			WithEntities(button).
+			WithAction(func(ctx context.Context, _ hal.EntityInterface) {
+				ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+				defer cancel()
+
+				errCh <- script.RunAndWait(ctx, nil)
+			}),
+	)
+
+	server.SendEvent(homeassistant.Event{
+		EventData: homeassistant.EventData{
+			EntityID: button.GetID(),
+			NewState: &homeassistant.State{EntityID: button.GetID(), State: "on"},
+		},
+	})
+
+	testutil.WaitFor(t, "verify script started", func() bool {
+		for _, msg := range server.MessagesReceived() {
+			if strings.Contains(string(msg), `"domain":"script"`) {
+				return true
+			}
+		}
+
+		return false
+	}, func() {})
+
+	server.SendEvent(homeassistant.Event{
+		EventData: homeassistant.EventData{
+			EntityID: script.GetID(),
+			NewState: &homeassistant.State{EntityID: script.GetID(), State: "off"},
+		},
+	})
+
+	select {
+	case err := <-errCh:
+		assert.NilError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("RunAndWait did not return after the script finished")
+	}
+
+	// Automations are dispatched again once the Action returns.
+	testutil.WaitFor(t, "verify script state applied", func() bool {
+		return !script.IsRunning()
+	}, func() {
+		spew.Dump(script.GetState())
+	})
+}
+
+func TestScript_RunAndWaitTimeout(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	script := hal.NewScript("script.bedtime")
+	conn.RegisterEntities(script)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, script.RunAndWait(ctx, nil), context.DeadlineExceeded)
+	assert.Assert(t, script.IsRunning())
+}
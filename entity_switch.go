@@ -0,0 +1,240 @@
+package hal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+)
+
+type SwitchInterface interface {
+	EntityInterface
+
+	IsOn() bool
+	IsOff() bool
+	TurnOn() error
+	TurnOnContext(ctx context.Context) error
+	TurnOff() error
+	TurnOffContext(ctx context.Context) error
+	Toggle() error
+	ToggleContext(ctx context.Context) error
+}
+
+// Switch is an entity in the switch domain, such as a smart plug or relay.
+type Switch struct {
+	*Entity
+}
+
+func NewSwitch(id string) *Switch {
+	return &Switch{Entity: NewEntity(id)}
+}
+
+func (s *Switch) IsOff() bool {
+	return s.GetState().State == "off"
+}
+
+func (s *Switch) IsOn() bool {
+	return s.GetState().State == "on"
+}
+
+func (s *Switch) TurnOn() error {
+	entityID := s.GetID()
+	if s.connection == nil {
+		logger.Error("Switch not registered", entityID)
+
+		return ErrEntityNotRegistered
+	}
+
+	logger.Info("Turning on switch", entityID)
+
+	if err := s.callService("switch", "turn_on", nil); err != nil {
+		logger.Error("Error turning on switch", entityID, "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *Switch) TurnOnContext(ctx context.Context) error {
+	if s.connection == nil {
+		logger.ErrorContext(ctx, "Switch not registered")
+
+		return ErrEntityNotRegistered
+	}
+
+	logger.InfoContext(ctx, "Turning on switch", "switch", s.GetID())
+
+	if err := s.callService("switch", "turn_on", nil); err != nil {
+		logger.ErrorContext(ctx, "Error turning on switch", "switch", s.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *Switch) TurnOff() error {
+	entityID := s.GetID()
+	if s.connection == nil {
+		logger.Error("Switch not registered", entityID)
+
+		return ErrEntityNotRegistered
+	}
+
+	logger.Info("Turning off switch", entityID)
+
+	if err := s.callService("switch", "turn_off", nil); err != nil {
+		logger.Error("Error turning off switch", entityID, "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *Switch) TurnOffContext(ctx context.Context) error {
+	if s.connection == nil {
+		logger.ErrorContext(ctx, "Switch not registered")
+
+		return ErrEntityNotRegistered
+	}
+
+	logger.InfoContext(ctx, "Turning off switch", "switch", s.GetID())
+
+	if err := s.callService("switch", "turn_off", nil); err != nil {
+		logger.ErrorContext(ctx, "Error turning off switch", "switch", s.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *Switch) Toggle() error {
+	entityID := s.GetID()
+	if s.connection == nil {
+		logger.Error("Switch not registered", entityID)
+
+		return ErrEntityNotRegistered
+	}
+
+	logger.Info("Toggling switch", entityID)
+
+	if err := s.callService("switch", "toggle", nil); err != nil {
+		logger.Error("Error toggling switch", entityID, "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *Switch) ToggleContext(ctx context.Context) error {
+	if s.connection == nil {
+		logger.ErrorContext(ctx, "Switch not registered")
+
+		return ErrEntityNotRegistered
+	}
+
+	logger.InfoContext(ctx, "Toggling switch", "switch", s.GetID())
+
+	if err := s.callService("switch", "toggle", nil); err != nil {
+		logger.ErrorContext(ctx, "Error toggling switch", "switch", s.GetID(), "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// SwitchGroup treats a set of switches as one.
+type SwitchGroup []SwitchInterface
+
+func (sg SwitchGroup) BindConnection(connection *Connection) {
+	for _, s := range sg {
+		s.BindConnection(connection)
+	}
+}
+
+func (sg SwitchGroup) GetID() string {
+	if len(sg) == 0 {
+		return "(empty switch group)"
+	}
+
+	ids := make([]string, len(sg))
+	for i, s := range sg {
+		ids[i] = s.GetID()
+	}
+
+	return strings.Join(ids, ", ")
+}
+
+func (sg SwitchGroup) GetState() homeassistant.State {
+	if len(sg) == 0 {
+		return homeassistant.State{}
+	}
+
+	return sg[0].GetState()
+}
+
+func (sg SwitchGroup) SetState(state homeassistant.State) {
+	for _, s := range sg {
+		s.SetState(state)
+	}
+}
+
+// IsOn returns true if all switches in the group are on.
+func (sg SwitchGroup) IsOn() bool {
+	for _, s := range sg {
+		if !s.IsOn() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsOff returns true if all switches in the group are off.
+func (sg SwitchGroup) IsOff() bool {
+	for _, s := range sg {
+		if !s.IsOff() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (sg SwitchGroup) TurnOn() error {
+	return eachMember(sg, SwitchInterface.TurnOn)
+}
+
+func (sg SwitchGroup) TurnOnContext(ctx context.Context) error {
+	return eachMember(sg, func(s SwitchInterface) error {
+		return s.TurnOnContext(ctx)
+	})
+}
+
+func (sg SwitchGroup) TurnOff() error {
+	return eachMember(sg, SwitchInterface.TurnOff)
+}
+
+func (sg SwitchGroup) TurnOffContext(ctx context.Context) error {
+	return eachMember(sg, func(s SwitchInterface) error {
+		return s.TurnOffContext(ctx)
+	})
+}
+
+// Toggle toggles each switch in the group individually. Use TurnOn or TurnOff
+// instead to bring switches that are out of sync into the same state.
+func (sg SwitchGroup) Toggle() error {
+	return eachMember(sg, SwitchInterface.Toggle)
+}
+
+func (sg SwitchGroup) ToggleContext(ctx context.Context) error {
+	return eachMember(sg, func(s SwitchInterface) error {
+		return s.ToggleContext(ctx)
+	})
+}
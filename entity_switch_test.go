@@ -0,0 +1,119 @@
+package hal_test
+
+import (
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestNewSwitch(t *testing.T) {
+	t.Parallel()
+	sw := hal.NewSwitch("switch.test")
+	assert.Equal(t, sw.GetID(), "switch.test")
+}
+
+func TestSwitch_IsOnIsOff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		state   string
+		wantOn  bool
+		wantOff bool
+	}{
+		{state: "on", wantOn: true, wantOff: false},
+		{state: "off", wantOn: false, wantOff: true},
+		{state: "unavailable", wantOn: false, wantOff: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.state, func(t *testing.T) {
+			t.Parallel()
+			sw := hal.NewSwitch("switch.test")
+			sw.SetState(homeassistant.State{State: tc.state})
+
+			assert.Equal(t, sw.IsOn(), tc.wantOn)
+			assert.Equal(t, sw.IsOff(), tc.wantOff)
+		})
+	}
+}
+
+func TestSwitch_NotRegistered(t *testing.T) {
+	t.Parallel()
+	sw := hal.NewSwitch("switch.test")
+
+	assert.Equal(t, sw.TurnOn(), hal.ErrEntityNotRegistered)
+	assert.Equal(t, sw.TurnOff(), hal.ErrEntityNotRegistered)
+	assert.Equal(t, sw.Toggle(), hal.ErrEntityNotRegistered)
+}
+
+func TestSwitch_ServiceCalls(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	sw := hal.NewSwitch("switch.test")
+	conn.RegisterEntities(sw)
+
+	assert.NilError(t, sw.TurnOn())
+	testutil.WaitFor(t, "verify switch turned on", sw.IsOn, func() {
+		spew.Dump(sw.GetState())
+	})
+
+	assert.NilError(t, sw.Toggle())
+	testutil.WaitFor(t, "verify switch toggled off", sw.IsOff, func() {
+		spew.Dump(sw.GetState())
+	})
+}
+
+func TestSwitchGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns joined IDs", func(t *testing.T) {
+		t.Parallel()
+		sg := hal.SwitchGroup{hal.NewSwitch("switch.1"), hal.NewSwitch("switch.2")}
+		assert.Equal(t, sg.GetID(), "switch.1, switch.2")
+		assert.Equal(t, hal.SwitchGroup{}.GetID(), "(empty switch group)")
+	})
+
+	t.Run("is on or off only when all members agree", func(t *testing.T) {
+		t.Parallel()
+		switch1 := hal.NewSwitch("switch.1")
+		switch2 := hal.NewSwitch("switch.2")
+		sg := hal.SwitchGroup{switch1, switch2}
+
+		switch1.SetState(homeassistant.State{State: "on"})
+		switch2.SetState(homeassistant.State{State: "off"})
+		assert.Equal(t, sg.IsOn(), false)
+		assert.Equal(t, sg.IsOff(), false)
+
+		switch2.SetState(homeassistant.State{State: "on"})
+		assert.Equal(t, sg.IsOn(), true)
+	})
+
+	t.Run("collects errors from individual switches", func(t *testing.T) {
+		t.Parallel()
+		sg := hal.SwitchGroup{hal.NewSwitch("switch.1"), hal.NewSwitch("switch.2")}
+		assert.ErrorContains(t, sg.TurnOn(), "entity not registered")
+		assert.NilError(t, hal.SwitchGroup{}.TurnOff())
+	})
+
+	t.Run("turns on all switches", func(t *testing.T) {
+		t.Parallel()
+
+		conn, _, cleanup := testutil.NewClientServer(t)
+		defer cleanup()
+
+		sg := hal.SwitchGroup{hal.NewSwitch("switch.1"), hal.NewSwitch("switch.2")}
+		conn.RegisterEntities(sg[0], sg[1])
+
+		assert.NilError(t, sg.TurnOn())
+		testutil.WaitFor(t, "verify switches turned on", sg.IsOn, func() {
+			spew.Dump(sg[0].GetState(), sg[1].GetState())
+		})
+	})
+}
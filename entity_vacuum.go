@@ -0,0 +1,134 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// Vacuum feature flags, as advertised in the supported_features attribute.
+const (
+	VacuumFeatureTurnOn = 1 << iota
+	VacuumFeatureTurnOff
+	VacuumFeaturePause
+	VacuumFeatureStop
+	VacuumFeatureReturnHome
+	VacuumFeatureFanSpeed
+	VacuumFeatureBattery
+	VacuumFeatureStatus
+	VacuumFeatureSendCommand
+	VacuumFeatureLocate
+	VacuumFeatureCleanSpot
+	VacuumFeatureMap
+	VacuumFeatureState
+	VacuumFeatureStart
+	VacuumFeatureCleanArea
+)
+
+// Vacuum states.
+const (
+	VacuumStateCleaning  = "cleaning"
+	VacuumStateDocked    = "docked"
+	VacuumStateIdle      = "idle"
+	VacuumStatePaused    = "paused"
+	VacuumStateReturning = "returning"
+	VacuumStateError     = "error"
+)
+
+// Vacuum is a robot vacuum cleaner.
+type Vacuum struct {
+	*Entity
+}
+
+func NewVacuum(id string) *Vacuum {
+	return &Vacuum{Entity: NewEntity(id)}
+}
+
+func (v *Vacuum) IsCleaning() bool {
+	return v.GetState().State == VacuumStateCleaning
+}
+
+func (v *Vacuum) IsDocked() bool {
+	return v.GetState().State == VacuumStateDocked
+}
+
+func (v *Vacuum) IsReturning() bool {
+	return v.GetState().State == VacuumStateReturning
+}
+
+func (v *Vacuum) IsPaused() bool {
+	return v.GetState().State == VacuumStatePaused
+}
+
+// HasError returns true if the vacuum is stuck or otherwise needs attention.
+func (v *Vacuum) HasError() bool {
+	return v.GetState().State == VacuumStateError
+}
+
+// BatteryLevel returns the battery level, between 0 and 100. The second return
+// value is false if the vacuum does not report one.
+func (v *Vacuum) BatteryLevel() (int, bool) {
+	level, ok := getFloat(v.GetState().Attributes["battery_level"])
+
+	return int(level), ok
+}
+
+func (v *Vacuum) FanSpeed() string {
+	return getString(v.GetState().Attributes["fan_speed"])
+}
+
+func (v *Vacuum) Start(ctx context.Context) error {
+	logger.InfoContext(ctx, "Starting vacuum", "vacuum", v.GetID())
+
+	return v.call(ctx, VacuumFeatureStart, "start", nil)
+}
+
+func (v *Vacuum) Pause(ctx context.Context) error {
+	logger.InfoContext(ctx, "Pausing vacuum", "vacuum", v.GetID())
+
+	return v.call(ctx, VacuumFeaturePause, "pause", nil)
+}
+
+func (v *Vacuum) Stop(ctx context.Context) error {
+	logger.InfoContext(ctx, "Stopping vacuum", "vacuum", v.GetID())
+
+	return v.call(ctx, VacuumFeatureStop, "stop", nil)
+}
+
+// ReturnToBase sends the vacuum back to its dock.
+func (v *Vacuum) ReturnToBase(ctx context.Context) error {
+	logger.InfoContext(ctx, "Returning vacuum to base", "vacuum", v.GetID())
+
+	return v.call(ctx, VacuumFeatureReturnHome, "return_to_base", nil)
+}
+
+// CleanArea cleans the given Home Assistant areas, for vacuums whose map
+// segments have been assigned to areas.
+func (v *Vacuum) CleanArea(ctx context.Context, areaIDs ...string) error {
+	if len(areaIDs) == 0 {
+		return fmt.Errorf("%w: no areas to clean", ErrUnsupportedValue)
+	}
+
+	logger.InfoContext(ctx, "Cleaning areas", "vacuum", v.GetID(), "areas", areaIDs)
+
+	return v.call(ctx, VacuumFeatureCleanArea, "clean_area", map[string]any{"cleaning_area_id": areaIDs})
+}
+
+// call calls a vacuum service if the vacuum supports the feature it requires.
+func (v *Vacuum) call(ctx context.Context, feature int, service string, data map[string]any) error {
+	if !v.supportsFeature(feature) {
+		err := fmt.Errorf("%w: vacuum.%s", ErrFeatureNotSupported, service)
+		logger.ErrorContext(ctx, "Vacuum does not support service", "vacuum", v.GetID(), "error", err)
+
+		return err
+	}
+
+	if err := v.callService("vacuum", service, data); err != nil {
+		logger.ErrorContext(ctx, "Error calling vacuum service", "vacuum", v.GetID(), "service", service, "error", err)
+
+		return err
+	}
+
+	return nil
+}
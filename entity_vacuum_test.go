@@ -0,0 +1,70 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestVacuum_ServiceCalls(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	vacuum := hal.NewVacuum("vacuum.downstairs")
+	conn.RegisterEntities(vacuum)
+
+	ctx := context.Background()
+
+	assert.ErrorIs(t, vacuum.Start(ctx), hal.ErrFeatureNotSupported)
+
+	// Seed the server so supported_features survives the generated state changes.
+	state := homeassistant.State{
+		EntityID: vacuum.GetID(),
+		State:    hal.VacuumStateDocked,
+		Attributes: map[string]any{
+			"supported_features": float64(hal.VacuumFeatureStart | hal.VacuumFeaturePause |
+				hal.VacuumFeatureReturnHome | hal.VacuumFeatureBattery),
+			"battery_level": float64(87),
+		},
+	}
+	server.SendEvent(homeassistant.Event{
+		EventType: "state_changed",
+		EventData: homeassistant.EventData{
+			EntityID: vacuum.GetID(),
+			NewState: &state,
+		},
+	})
+
+	testutil.WaitFor(t, "verify vacuum docked", vacuum.IsDocked, func() {
+		spew.Dump(vacuum.GetState())
+	})
+
+	battery, ok := vacuum.BatteryLevel()
+	assert.Assert(t, ok)
+	assert.Equal(t, battery, 87)
+
+	assert.ErrorIs(t, vacuum.Stop(ctx), hal.ErrFeatureNotSupported)
+	assert.ErrorIs(t, vacuum.CleanArea(ctx, "kitchen"), hal.ErrFeatureNotSupported)
+
+	assert.NilError(t, vacuum.Start(ctx))
+	testutil.WaitFor(t, "verify vacuum cleaning", vacuum.IsCleaning, func() {
+		spew.Dump(vacuum.GetState())
+	})
+
+	assert.NilError(t, vacuum.Pause(ctx))
+	testutil.WaitFor(t, "verify vacuum paused", vacuum.IsPaused, func() {
+		spew.Dump(vacuum.GetState())
+	})
+
+	assert.NilError(t, vacuum.ReturnToBase(ctx))
+	testutil.WaitFor(t, "verify vacuum returning", vacuum.IsReturning, func() {
+		spew.Dump(vacuum.GetState())
+	})
+}
@@ -0,0 +1,149 @@
+package hal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dansimau/hal/logger"
+)
+
+// Weather feature flags, as advertised in the supported_features attribute.
+const (
+	WeatherFeatureForecastDaily = 1 << iota
+	WeatherFeatureForecastHourly
+	WeatherFeatureForecastTwiceDaily
+)
+
+// ForecastType is the granularity of a weather forecast.
+type ForecastType string
+
+const (
+	ForecastDaily      ForecastType = "daily"
+	ForecastHourly     ForecastType = "hourly"
+	ForecastTwiceDaily ForecastType = "twice_daily"
+)
+
+// Forecast is the weather forecast for a single period. Values that the
+// weather provider does not supply are zero.
+type Forecast struct {
+	Datetime                 time.Time `json:"datetime"`
+	Condition                string    `json:"condition"`
+	Temperature              float64   `json:"temperature"`
+	TemperatureLow           float64   `json:"templow"`
+	ApparentTemperature      float64   `json:"apparent_temperature"`
+	Humidity                 float64   `json:"humidity"`
+	Precipitation            float64   `json:"precipitation"`
+	PrecipitationProbability float64   `json:"precipitation_probability"`
+	CloudCoverage            float64   `json:"cloud_coverage"`
+	UVIndex                  float64   `json:"uv_index"`
+	WindSpeed                float64   `json:"wind_speed"`
+	WindBearing              float64   `json:"wind_bearing"`
+
+	// IsDaytime is set for twice daily forecasts, to tell the day and night
+	// periods apart.
+	IsDaytime *bool `json:"is_daytime,omitempty"`
+}
+
+// Weather is a weather provider, with current conditions and forecasts.
+type Weather struct {
+	*Entity
+}
+
+func NewWeather(id string) *Weather {
+	return &Weather{Entity: NewEntity(id)}
+}
+
+// Condition returns the current condition, e.g. "sunny" or "rainy".
+func (w *Weather) Condition() string {
+	return w.GetState().State
+}
+
+// Temperature returns the current temperature, in TemperatureUnit. The second
+// return value is false if the provider does not report one.
+func (w *Weather) Temperature() (float64, bool) {
+	return getFloat(w.GetState().Attributes["temperature"])
+}
+
+func (w *Weather) TemperatureUnit() string {
+	return getString(w.GetState().Attributes["temperature_unit"])
+}
+
+func (w *Weather) ApparentTemperature() (float64, bool) {
+	return getFloat(w.GetState().Attributes["apparent_temperature"])
+}
+
+func (w *Weather) Humidity() (float64, bool) {
+	return getFloat(w.GetState().Attributes["humidity"])
+}
+
+func (w *Weather) Pressure() (float64, bool) {
+	return getFloat(w.GetState().Attributes["pressure"])
+}
+
+func (w *Weather) CloudCoverage() (float64, bool) {
+	return getFloat(w.GetState().Attributes["cloud_coverage"])
+}
+
+func (w *Weather) UVIndex() (float64, bool) {
+	return getFloat(w.GetState().Attributes["uv_index"])
+}
+
+func (w *Weather) WindSpeed() (float64, bool) {
+	return getFloat(w.GetState().Attributes["wind_speed"])
+}
+
+func (w *Weather) WindBearing() (float64, bool) {
+	return getFloat(w.GetState().Attributes["wind_bearing"])
+}
+
+// Forecast fetches the forecast of the given type from the weather provider.
+func (w *Weather) Forecast(ctx context.Context, kind ForecastType) ([]Forecast, error) {
+	feature := map[ForecastType]int{
+		ForecastDaily:      WeatherFeatureForecastDaily,
+		ForecastHourly:     WeatherFeatureForecastHourly,
+		ForecastTwiceDaily: WeatherFeatureForecastTwiceDaily,
+	}[kind]
+
+	if feature == 0 {
+		return nil, fmt.Errorf("%w: forecast type %q", ErrUnsupportedValue, kind)
+	}
+
+	if !w.supportsFeature(feature) {
+		err := fmt.Errorf("%w: %s forecast", ErrFeatureNotSupported, kind)
+		logger.ErrorContext(ctx, "Weather does not support forecast type", "weather", w.GetID(), "error", err)
+
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Fetching forecast", "weather", w.GetID(), "type", kind)
+
+	response, err := w.callServiceWithResponse("weather", "get_forecasts", map[string]any{"type": kind})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error fetching forecast", "weather", w.GetID(), "error", err)
+
+		return nil, err
+	}
+
+	if len(response) == 0 {
+		return nil, fmt.Errorf("no forecast returned for %s", w.GetID())
+	}
+
+	// The response is keyed by entity ID, since the service can target
+	// several weather entities at once.
+	var forecasts map[string]struct {
+		Forecast []Forecast `json:"forecast"`
+	}
+
+	if err := json.Unmarshal(response, &forecasts); err != nil {
+		return nil, fmt.Errorf("invalid forecast response: %w", err)
+	}
+
+	forecast, ok := forecasts[w.GetID()]
+	if !ok {
+		return nil, fmt.Errorf("no forecast returned for %s", w.GetID())
+	}
+
+	return forecast.Forecast, nil
+}
@@ -0,0 +1,79 @@
+package hal_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"gotest.tools/v3/assert"
+)
+
+func TestWeather_Forecast(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	weather := hal.NewWeather("weather.home")
+	conn.RegisterEntities(weather)
+
+	weather.SetState(homeassistant.State{
+		EntityID: "weather.home",
+		State:    "cloudy",
+		Attributes: map[string]any{
+			"supported_features": float64(hal.WeatherFeatureForecastDaily),
+			"temperature":        float64(12.5),
+			"temperature_unit":   "°C",
+		},
+	})
+
+	assert.Equal(t, weather.Condition(), "cloudy")
+
+	temperature, ok := weather.Temperature()
+	assert.Assert(t, ok)
+	assert.Equal(t, temperature, 12.5)
+
+	ctx := context.Background()
+
+	_, err := weather.Forecast(ctx, hal.ForecastHourly)
+	assert.ErrorIs(t, err, hal.ErrFeatureNotSupported)
+
+	_, err = weather.Forecast(ctx, "weekly")
+	assert.ErrorIs(t, err, hal.ErrUnsupportedValue)
+
+	// A response for another entity.
+	assert.NilError(t, server.SetServiceResponse("weather.get_forecasts", map[string]any{
+		"weather.other": map[string]any{"forecast": []map[string]any{}},
+	}))
+
+	_, err = weather.Forecast(ctx, hal.ForecastDaily)
+	assert.ErrorContains(t, err, "no forecast returned for weather.home")
+
+	tomorrow := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.NilError(t, server.SetServiceResponse("weather.get_forecasts", map[string]any{
+		"weather.home": map[string]any{
+			"forecast": []map[string]any{
+				{
+					"datetime":                  tomorrow.Format(time.RFC3339),
+					"condition":                 "rainy",
+					"temperature":               9.0,
+					"templow":                   3.5,
+					"precipitation_probability": 80,
+				},
+			},
+		},
+	}))
+
+	forecast, err := weather.Forecast(ctx, hal.ForecastDaily)
+	assert.NilError(t, err)
+	assert.Equal(t, weather.Condition(), "cloudy")
+	assert.Equal(t, len(forecast), 1)
+	assert.Assert(t, forecast[0].Datetime.Equal(tomorrow))
+	assert.Equal(t, forecast[0].Condition, "rainy")
+	assert.Equal(t, forecast[0].TemperatureLow, 3.5)
+	assert.Equal(t, forecast[0].PrecipitationProbability, 80.0)
+}
@@ -2,4 +2,9 @@ package hal
 
 import "errors"
 
-var ErrEntityNotRegistered = errors.New("entity not registered")
+var (
+	ErrEntityNotRegistered = errors.New("entity not registered")
+	ErrFeatureNotSupported = errors.New("feature not supported by entity")
+	ErrUnsupportedValue    = errors.New("value not supported by entity")
+	ErrValueOutOfRange     = errors.New("value out of range")
+)
@@ -0,0 +1,71 @@
+package hal
+
+import (
+	"context"
+
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+	"github.com/dansimau/hal/store"
+)
+
+// EventTrigger runs an automation when Home Assistant fires an event of the
+// given type, e.g. "zha_event" for a button press. Filter, if set, restricts
+// it to matching events.
+type EventTrigger struct {
+	EventType string
+	Filter    func(event homeassistant.Event) bool
+}
+
+// EventAutomation is an automation that also runs on Home Assistant events.
+// Events call EventAction instead of Action, as they have no trigger entity.
+type EventAutomation interface {
+	Automation
+
+	EventTriggers() []EventTrigger
+
+	// EventAction is called for each event that matches one of the triggers.
+	EventAction(ctx context.Context, event homeassistant.Event)
+}
+
+// registerEventTriggers subscribes to the event types of the automation's
+// event triggers. Subscriptions are made on connect and restored after every
+// reconnect.
+func (h *Connection) registerEventTriggers(automation EventAutomation) {
+	for _, trigger := range automation.EventTriggers() {
+		h.OnEvent(trigger.EventType, func(event homeassistant.Event) {
+			h.evaluateEventTrigger(automation, trigger, event)
+		})
+	}
+}
+
+// evaluateEventTrigger runs the automation if the event matches the trigger.
+// Events fired by hal itself are ignored, so that an automation firing an
+// event it listens for does not loop. Device events such as "zha_event" have
+// no user, so they are only skipped if hal's user is configured. The caller
+// must hold mutex.
+func (h *Connection) evaluateEventTrigger(automation EventAutomation, trigger EventTrigger, event homeassistant.Event) {
+	if h.config.HomeAssistant.UserID != "" && event.Context.UserID == h.config.HomeAssistant.UserID {
+		logger.Debug("Skipping automation from own event", "", "automation", automation.Name(), "event_type", event.EventType)
+
+		return
+	}
+
+	if trigger.Filter != nil && !trigger.Filter(event) {
+		return
+	}
+
+	h.runEventAutomation(automation, event)
+}
+
+// runEventAutomation runs an automation that was triggered by an event. The
+// caller must hold mutex.
+func (h *Connection) runEventAutomation(automation EventAutomation, event homeassistant.Event) {
+	// Create context with tracing metadata
+	ctx := NewAutomationContext(event.EventData.EntityID, automation.Name())
+	ctx = WithEvent(ctx, event)
+
+	logger.InfoContext(ctx, "Running event automation", "event_type", event.EventType)
+	// Record automation triggered metric
+	h.metricsService.RecordCounter(store.MetricTypeAutomationTriggered, event.EventData.EntityID, automation.Name())
+	automation.EventAction(ctx, event)
+}
@@ -0,0 +1,100 @@
+package hal_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/hassws"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+type zhaEvent struct {
+	DeviceIEEE string `json:"device_ieee"`
+	Command    string `json:"command"`
+}
+
+// sendZHAEvent sends a button event, fired by the given user.
+func sendZHAEvent(server *hassws.Server, command, userID string) {
+	event := homeassistant.Event{
+		EventType: "zha_event",
+		EventData: homeassistant.EventData{
+			Raw: json.RawMessage(`{"device_ieee":"00:11:22","command":"` + command + `"}`),
+		},
+	}
+	event.Context.UserID = userID
+
+	server.SendEvent(event)
+}
+
+func TestEventAutomation(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewFastReconnectClientServer(t)
+	defer cleanup()
+
+	var (
+		mutex    sync.Mutex
+		commands []string
+	)
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("button.pressed").
+			WithEventTrigger("zha_event", func(event homeassistant.Event) bool {
+				var data zhaEvent
+
+				return event.EventData.Decode(&data) == nil && data.Command != "release"
+			}).
+			WithAction(func(context.Context, hal.EntityInterface) {
+				t.Error("Action called for an event")
+			}).
+			WithEventAction(func(ctx context.Context, event homeassistant.Event) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				assert.Equal(t, hal.GetAutomationNameFromContext(ctx), "button.pressed")
+
+				var data zhaEvent
+				assert.NilError(t, event.EventData.Decode(&data))
+
+				commands = append(commands, data.Command)
+			}),
+	)
+
+	waitForCommands := func(want ...string) {
+		t.Helper()
+
+		testutil.WaitFor(t, "verify event automation runs", func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			return spew.Sdump(commands) == spew.Sdump(want)
+		}, func() {
+			spew.Dump(commands)
+		})
+	}
+
+	// Filtered out, and fired by hal itself.
+	sendZHAEvent(server, "release", "")
+	sendZHAEvent(server, "on", testutil.TestUserID)
+	sendZHAEvent(server, "on", "")
+	waitForCommands("on")
+
+	// The subscription is restored after a reconnect.
+	assert.NilError(t, server.DisconnectClient())
+
+	testutil.WaitFor(t, "verify reconnected", func() bool {
+		return conn.GetReconnectAttempts() >= 1 && server.GetSubscriptionCount() >= 2
+	}, func() {
+		spew.Dump(conn.GetReconnectAttempts(), server.GetSubscriptionCount())
+	})
+
+	sendZHAEvent(server, "off", "")
+	waitForCommands("on", "off")
+}
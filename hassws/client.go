@@ -48,6 +48,7 @@ const (
 
 var (
 	ErrAuthInvalid        = errors.New("invalid access token")
+	ErrCallServiceFailed  = errors.New("call service failed")
 	ErrNotConnected       = errors.New("websocket not connected")
 	ErrReadTimeout        = errors.New("read timeout")
 	ErrUnexpectedResponse = errors.New("unexpected response")
@@ -534,7 +535,7 @@ func (c *Client) SubscribeEvents(eventType string, handler func(EventMessage)) e
 		}
 	}(responseChan)
 
-	logger.Info("Listening for state changes", "")
+	logger.Info("Listening for events", "", "event_type", eventType)
 
 	return nil
 }
@@ -589,6 +590,9 @@ func (c *Client) SubscribeEventsRaw(eventType string, handler func([]byte)) erro
 	return nil
 }
 
+// CallService calls a Home Assistant service and waits for the result. If Home
+// Assistant reports that the call failed, the response is returned along with
+// an error wrapping ErrCallServiceFailed.
 func (c *Client) CallService(msg CallServiceRequest) (CallServiceResponse, error) {
 	if c.getState() != stateConnected {
 		return CallServiceResponse{}, ErrNotConnected
@@ -610,7 +614,7 @@ func (c *Client) CallService(msg CallServiceRequest) (CallServiceResponse, error
 	}
 
 	if resp.Type == MessageTypeResult && !resp.Success {
-		logger.Error("Call service failed", "", "err", resp.Error)
+		return resp, fmt.Errorf("%w: %s.%s: %v: %v", ErrCallServiceFailed, msg.Domain, msg.Service, resp.Error["code"], resp.Error["message"])
 	}
 
 	return resp, nil
@@ -76,6 +76,11 @@ type CallServiceRequest struct {
 	Service string            `json:"service"`
 	Data    map[string]any    `json:"service_data,omitempty"`
 	Target  map[string]string `json:"target,omitempty"`
+
+	// ReturnResponse asks Home Assistant to return the service response, for
+	// services that provide one (e.g. weather.get_forecasts). It is returned
+	// in CallServiceResponse.Result.Response.
+	ReturnResponse bool `json:"return_response,omitempty"`
 }
 
 type CallServiceResponse struct {
@@ -86,6 +91,7 @@ type CallServiceResponse struct {
 		Context struct {
 			ID string `json:"id"`
 		} `json:"context"`
+		Response json.RawMessage `json:"response,omitempty"`
 	} `json:"result"`
 	Error map[string]any `json:"error,omitempty"`
 }
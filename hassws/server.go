@@ -3,9 +3,12 @@ package hassws
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,14 +29,27 @@ type Server struct {
 	messagesReceived [][]byte
 	messagesSent     [][]byte
 
-	// Subscribers is a list of message IDs that initiated a subscription.
-	subscribers []int
+	// Subscribers is a list of subscriptions, identified by the message ID
+	// that initiated them.
+	subscribers []subscription
 
 	// validUsers maps auth tokens to user IDs
 	validUsers map[string]string
 	// authenticatedUserID stores the user ID of the authenticated client
 	authenticatedUserID string
 
+	// states holds the last known state of each entity, so that service calls
+	// can be simulated relative to the current state (e.g. toggle).
+	states map[string]homeassistant.State
+
+	// serviceErrors maps services ("domain.service") to the error returned
+	// when they are called, to simulate failed service calls.
+	serviceErrors map[string]map[string]any
+
+	// serviceResponses maps services ("domain.service") to the response
+	// returned when they are called with return_response.
+	serviceResponses map[string]json.RawMessage
+
 	// respondToPings controls whether the server replies to ping messages.
 	// Setting it to false simulates a "stuck" connection that remains open but
 	// stops delivering data, exercising the client's staleness detection.
@@ -42,12 +58,22 @@ type Server struct {
 	lock sync.RWMutex
 }
 
+// subscription is an event subscription. An empty event type subscribes to
+// all events, as in Home Assistant.
+type subscription struct {
+	id        int
+	eventType string
+}
+
 func NewServer(validUsers map[string]string) (*Server, error) {
 	server := &Server{
 		http: &http.Server{
 			ReadHeaderTimeout: readHeaderTimeoutSeconds * time.Second,
 		},
-		validUsers: validUsers,
+		validUsers:       validUsers,
+		states:           make(map[string]homeassistant.State),
+		serviceErrors:    make(map[string]map[string]any),
+		serviceResponses: make(map[string]json.RawMessage),
 	}
 
 	server.respondToPings.Store(true)
@@ -78,7 +104,10 @@ func (s *Server) handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.lock.Lock()
 	s.websocket = conn
+	s.lock.Unlock()
+
 	defer conn.Close()
 
 	if err := s.handleAuthentication(conn); err != nil {
@@ -87,10 +116,10 @@ func (s *Server) handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.listen()
+	s.listen(conn)
 }
 
-func (s *Server) listen() {
+func (s *Server) listen(conn *websocket.Conn) {
 	// Clear subscribers when connection closes
 	defer func() {
 		s.lock.Lock()
@@ -99,7 +128,7 @@ func (s *Server) listen() {
 	}()
 
 	for {
-		_, messageBytes, err := s.websocket.ReadMessage()
+		_, messageBytes, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
 				log.Println("[Server] Received close message, bye")
@@ -130,61 +159,57 @@ func (s *Server) listen() {
 
 		switch cmd.Type {
 		case MessageTypeCallService:
-			s.SendMessage(CallServiceResponse{
-				ID:      cmd.ID,
-				Type:    MessageTypeResult,
-				Success: true,
-			})
-
 			var callServiceMessage CallServiceRequest
 			if err := json.Unmarshal(messageBytes, &callServiceMessage); err != nil {
 				panic(err)
 			}
 
-			entityIDs := []string{}
-			attributes := map[string]any{}
+			s.lock.RLock()
+			serviceError, fail := s.serviceErrors[callServiceMessage.Domain+"."+callServiceMessage.Service]
+			s.lock.RUnlock()
 
-			for _, entityID := range callServiceMessage.Data["entity_id"].([]interface{}) {
-				entityIDs = append(entityIDs, entityID.(string))
+			if fail {
+				s.SendMessage(CallServiceResponse{
+					ID:      cmd.ID,
+					Type:    MessageTypeResult,
+					Success: false,
+					Error:   serviceError,
+				})
+
+				continue
 			}
 
-			for k, v := range callServiceMessage.Data {
-				if k == "entity_id" {
-					continue
-				}
+			response := CallServiceResponse{
+				ID:      cmd.ID,
+				Type:    MessageTypeResult,
+				Success: true,
+			}
 
-				attributes[k] = v
+			if callServiceMessage.ReturnResponse {
+				s.lock.RLock()
+				response.Result.Response = s.serviceResponses[callServiceMessage.Domain+"."+callServiceMessage.Service]
+				s.lock.RUnlock()
 			}
 
-			state := ""
+			s.SendMessage(response)
 
-			switch callServiceMessage.Service {
-			case "turn_on":
-				state = "on"
-			case "turn_off":
-				state = "off"
+			// Services called for their response (e.g. weather.get_forecasts)
+			// do not change state.
+			if !callServiceMessage.ReturnResponse {
+				s.handleCallService(callServiceMessage)
 			}
 
-			// Generate state updates
-			for _, entityID := range entityIDs {
-				s.SendEvent(homeassistant.Event{
-					Context: homeassistant.EventMessageContext{
-						UserID: s.authenticatedUserID,
-					},
-					EventData: homeassistant.EventData{
-						EntityID: entityID,
-						NewState: &homeassistant.State{
-							EntityID:   entityID,
-							State:      state,
-							Attributes: attributes,
-						},
-					},
-				})
+		case MessageTypeSubscribeEvents:
+			var subscribeMessage subscribeEventsRequest
+			if err := json.Unmarshal(messageBytes, &subscribeMessage); err != nil {
+				panic(err)
 			}
 
-		case MessageTypeSubscribeEvents:
 			s.lock.Lock()
-			s.subscribers = append(s.subscribers, cmd.ID)
+			s.subscribers = append(s.subscribers, subscription{
+				id:        cmd.ID,
+				eventType: subscribeMessage.EventType,
+			})
 			s.lock.Unlock()
 
 			s.SendMessage(subscribeEventsResponse{
@@ -194,14 +219,18 @@ func (s *Server) listen() {
 			})
 
 		case MessageTypeGetStates:
+			result, err := json.Marshal(s.getStates())
+			if err != nil {
+				logger.Error("Error marshalling states", "", "error", err)
+
+				continue
+			}
+
 			s.SendMessage(CommandResponse{
 				ID:      cmd.ID,
 				Type:    MessageTypeResult,
 				Success: true,
-				// TODO: Either keep state on the server site, or allow testers
-				// to set it. For now we just leave it empty so tests don't
-				// crash.
-				Result: json.RawMessage("[]"),
+				Result:  result,
 			})
 
 		case MessageTypePing:
@@ -220,13 +249,225 @@ func (s *Server) listen() {
 	}
 }
 
+// handleCallService simulates the effect of a service call on the targeted
+// entities and sends a state change event for each of them.
+func (s *Server) handleCallService(msg CallServiceRequest) {
+	entityIDs := []string{}
+	attributes := map[string]any{}
+
+	switch ids := msg.Data["entity_id"].(type) {
+	case string:
+		entityIDs = append(entityIDs, ids)
+	case []any:
+		for _, entityID := range ids {
+			if id, ok := entityID.(string); ok {
+				entityIDs = append(entityIDs, id)
+			}
+		}
+	}
+
+	for k, v := range msg.Data {
+		if k == "entity_id" {
+			continue
+		}
+
+		attributes[k] = v
+	}
+
+	for _, entityID := range entityIDs {
+		s.lock.Lock()
+		oldState, exists := s.states[entityID]
+		newState := copyState(oldState)
+		newState.EntityID = entityID
+		newState.Update(simulateServiceCall(msg, oldState, attributes))
+		s.states[entityID] = newState
+		s.lock.Unlock()
+
+		eventData := homeassistant.EventData{
+			EntityID: entityID,
+			NewState: &newState,
+		}
+
+		if exists {
+			eventData.OldState = &oldState
+		}
+
+		s.lock.RLock()
+		userID := s.authenticatedUserID
+		s.lock.RUnlock()
+
+		s.SendEvent(homeassistant.Event{
+			EventType: homeassistant.EventTypeStateChanged,
+			Context: homeassistant.EventMessageContext{
+				UserID: userID,
+			},
+			EventData: eventData,
+		})
+	}
+}
+
+// simulateServiceCall returns the state update an entity receives as a result
+// of the given service being called on it. By default the service data is
+// applied as attributes; an empty state leaves the current state as-is.
+func simulateServiceCall(msg CallServiceRequest, current homeassistant.State, data map[string]any) homeassistant.State {
+	update := homeassistant.State{Attributes: data}
+
+	switch msg.Domain + "." + msg.Service {
+	case "climate.set_hvac_mode":
+		update.State, _ = data["hvac_mode"].(string)
+
+		return update
+
+	case "cover.open_cover":
+		return homeassistant.State{State: "open", Attributes: map[string]any{"current_position": float64(100)}}
+	case "cover.close_cover":
+		return homeassistant.State{State: "closed", Attributes: map[string]any{"current_position": float64(0)}}
+	case "cover.stop_cover":
+		return homeassistant.State{}
+	case "cover.set_cover_position":
+		position, _ := data["position"].(float64)
+
+		update = homeassistant.State{State: "open", Attributes: map[string]any{"current_position": position}}
+		if position == 0 {
+			update.State = "closed"
+		}
+
+		return update
+	case "cover.open_cover_tilt":
+		return homeassistant.State{Attributes: map[string]any{"current_tilt_position": float64(100)}}
+	case "cover.close_cover_tilt":
+		return homeassistant.State{Attributes: map[string]any{"current_tilt_position": float64(0)}}
+	case "cover.set_cover_tilt_position":
+		return homeassistant.State{Attributes: map[string]any{"current_tilt_position": data["tilt_position"]}}
+
+	case "lock.lock":
+		update.State = "locked"
+
+		return update
+	case "lock.unlock":
+		update.State = "unlocked"
+
+		return update
+	case "lock.open":
+		update.State = "open"
+
+		return update
+
+	case "media_player.media_play", "media_player.play_media":
+		update.State = "playing"
+
+		return update
+	case "media_player.media_pause":
+		update.State = "paused"
+
+		return update
+	case "media_player.media_stop":
+		update.State = "idle"
+
+		return update
+
+	case "alarm_control_panel.alarm_arm_home":
+		return homeassistant.State{State: "armed_home"}
+	case "alarm_control_panel.alarm_arm_away":
+		return homeassistant.State{State: "armed_away"}
+	case "alarm_control_panel.alarm_arm_night":
+		return homeassistant.State{State: "armed_night"}
+	case "alarm_control_panel.alarm_arm_vacation":
+		return homeassistant.State{State: "armed_vacation"}
+	case "alarm_control_panel.alarm_disarm":
+		return homeassistant.State{State: "disarmed"}
+	case "alarm_control_panel.alarm_trigger":
+		return homeassistant.State{State: "triggered"}
+
+	case "fan.set_percentage":
+		update.State = "on"
+		if percentage, _ := data["percentage"].(float64); percentage == 0 {
+			update.State = "off"
+		}
+
+		return update
+	case "fan.set_preset_mode":
+		update.State = "on"
+
+		return update
+
+	case "vacuum.start", "vacuum.clean_area":
+		return homeassistant.State{State: "cleaning"}
+	case "vacuum.pause":
+		return homeassistant.State{State: "paused"}
+	case "vacuum.stop":
+		return homeassistant.State{State: "idle"}
+	case "vacuum.return_to_base":
+		return homeassistant.State{State: "returning"}
+
+	case "input_number.set_value", "input_text.set_value":
+		return homeassistant.State{State: fmt.Sprint(data["value"])}
+	case "input_select.select_option":
+		return homeassistant.State{State: fmt.Sprint(data["option"])}
+	case "input_datetime.set_datetime":
+		for _, key := range []string{"datetime", "date", "time"} {
+			if value, ok := data[key].(string); ok {
+				return homeassistant.State{State: value}
+			}
+		}
+
+		return homeassistant.State{}
+	case "input_button.press", "scene.turn_on":
+		return homeassistant.State{State: time.Now().UTC().Format(time.RFC3339Nano)}
+	}
+
+	switch msg.Service {
+	case "turn_on":
+		update.State = "on"
+	case "turn_off":
+		update.State = "off"
+	case "toggle":
+		update.State = "on"
+		if current.State == "on" {
+			update.State = "off"
+		}
+	}
+
+	return update
+}
+
+// copyState returns a copy of the state that does not share its attributes
+// map with the original.
+func copyState(state homeassistant.State) homeassistant.State {
+	attributes := make(map[string]any, len(state.Attributes))
+	for k, v := range state.Attributes {
+		attributes[k] = v
+	}
+
+	state.Attributes = attributes
+
+	return state
+}
+
+// getStates returns the states the server holds, ordered by entity ID.
+func (s *Server) getStates() []homeassistant.State {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	states := make([]homeassistant.State, 0, len(s.states))
+	for _, state := range s.states {
+		states = append(states, copyState(state))
+	}
+
+	slices.SortFunc(states, func(a, b homeassistant.State) int {
+		return strings.Compare(a.EntityID, b.EntityID)
+	})
+
+	return states
+}
+
 func (s *Server) handleAuthentication(conn *websocket.Conn) error {
 	// Send auth_required message
 	authChallenge := AuthChallenge{
 		Type:      "auth_required",
 		HAVersion: "2024.1.0",
 	}
-	if err := conn.WriteJSON(authChallenge); err != nil {
+	if err := s.writeJSON(conn, authChallenge); err != nil {
 		return err
 	}
 
@@ -244,21 +485,36 @@ func (s *Server) handleAuthentication(conn *websocket.Conn) error {
 			Message:   "Invalid access token",
 			HAVersion: "2024.1.0",
 		}
-		return conn.WriteJSON(authResp)
+		return s.writeJSON(conn, authResp)
 	}
 
 	// Store authenticated user ID
+	s.lock.Lock()
 	s.authenticatedUserID = userID
+	s.lock.Unlock()
 
 	authResp := AuthResponse{
 		Type:      "auth_ok",
 		HAVersion: "2024.1.0",
 	}
 
-	return conn.WriteJSON(authResp)
+	return s.writeJSON(conn, authResp)
+}
+
+// writeJSON writes a message during the handshake. Writes to the connection
+// must not overlap with SendMessage or Close.
+func (s *Server) writeJSON(conn *websocket.Conn, message any) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return conn.WriteJSON(message)
 }
 
 func (s *Server) Close() error {
+	// Writes to the connection must not overlap with SendMessage.
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	return s.websocket.WriteMessage(
 		websocket.CloseMessage,
 		websocket.FormatCloseMessage(websocket.CloseNormalClosure, "bye"),
@@ -321,25 +577,89 @@ func (s *Server) SendMessage(message any) {
 	log.Printf("[Server] Sent message: %s", string(msgBytes))
 }
 
+// MessagesReceived returns a copy of the messages received so far. It is safe
+// to call while the client is connected.
 func (s *Server) MessagesReceived() [][]byte {
-	return s.messagesReceived
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return slices.Clone(s.messagesReceived)
 }
 
+// MessagesSent returns a copy of the messages sent so far.
 func (s *Server) MessagesSent() [][]byte {
-	return s.messagesSent
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return slices.Clone(s.messagesSent)
 }
 
-// SendEvent sends a state change event to the server.
+// SendEvent sends a state change event to the server. The new state is
+// recorded so that subsequent service calls are simulated relative to it.
 func (s *Server) SendEvent(event homeassistant.Event) {
-	for _, id := range s.subscribers {
+	if event.EventData.NewState != nil {
+		s.lock.Lock()
+		s.states[event.EventData.EntityID] = copyState(*event.EventData.NewState)
+		s.lock.Unlock()
+	}
+
+	// Events without a type are state changes, for convenience in tests.
+	if event.EventType == "" {
+		event.EventType = homeassistant.EventTypeStateChanged
+	}
+
+	s.lock.RLock()
+	subscribers := slices.Clone(s.subscribers)
+	s.lock.RUnlock()
+
+	for _, sub := range subscribers {
+		if sub.eventType != "" && sub.eventType != event.EventType {
+			continue
+		}
+
 		s.SendMessage(EventMessage{
-			ID:    id,
+			ID:    sub.id,
 			Type:  MessageTypeEvent,
 			Event: event,
 		})
 	}
 }
 
+// SetCallServiceError makes calls to the given service ("domain.service") fail
+// with the given error code and message, as Home Assistant does when e.g. a
+// device does not respond. Pass an empty code to make calls succeed again.
+func (s *Server) SetCallServiceError(service, code, message string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if code == "" {
+		delete(s.serviceErrors, service)
+
+		return
+	}
+
+	s.serviceErrors[service] = map[string]any{
+		"code":    code,
+		"message": message,
+	}
+}
+
+// SetServiceResponse sets the response returned by the given service
+// ("domain.service") when it is called with return_response.
+func (s *Server) SetServiceResponse(service string, response any) error {
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.serviceResponses[service] = responseBytes
+
+	return nil
+}
+
 // SetRespondToPings controls whether the server replies to ping messages.
 // Passing false simulates a "stuck" connection that remains open but stops
 // delivering data.
@@ -347,6 +667,16 @@ func (s *Server) SetRespondToPings(respond bool) {
 	s.respondToPings.Store(respond)
 }
 
+// SetState sets the state of an entity without sending a state change, e.g.
+// to simulate a change while the client is disconnected. The client receives
+// it when it next fetches all states.
+func (s *Server) SetState(state homeassistant.State) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.states[state.EntityID] = copyState(state)
+}
+
 // GetSubscriptionCount returns the number of active event subscriptions.
 func (s *Server) GetSubscriptionCount() int {
 	s.lock.RLock()
@@ -1,5 +1,7 @@
 package homeassistant
 
+import "encoding/json"
+
 type Event struct {
 	EventData EventData           `json:"data"`
 	EventType string              `json:"event_type"`
@@ -18,4 +20,37 @@ type EventData struct {
 	EntityID string `json:"entity_id"`
 	OldState *State `json:"old_state"`
 	NewState *State `json:"new_state"`
+
+	// Raw is the event data as received. Events other than state_changed
+	// carry fields of their own, which can be read with Decode. When set, Raw
+	// is also what the event data marshals to.
+	Raw json.RawMessage `json:"-"`
+}
+
+// eventData has the fields of EventData without its JSON methods.
+type eventData EventData
+
+func (d *EventData) UnmarshalJSON(b []byte) error {
+	var data eventData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+
+	*d = EventData(data)
+	d.Raw = append(json.RawMessage(nil), b...)
+
+	return nil
+}
+
+func (d EventData) MarshalJSON() ([]byte, error) {
+	if len(d.Raw) > 0 {
+		return d.Raw, nil
+	}
+
+	return json.Marshal(eventData(d))
+}
+
+// Decode unmarshals the raw event data into v.
+func (d EventData) Decode(v any) error {
+	return json.Unmarshal(d.Raw, v)
 }
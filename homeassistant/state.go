@@ -5,7 +5,15 @@ import (
 )
 
 const (
-	EventTypeStateChanged = "state_changed"
+	EventTypeStateChanged                = "state_changed"
+	EventTypeMobileAppNotificationAction = "mobile_app_notification_action"
+)
+
+// States that Home Assistant reports for any entity whose real state is not
+// known.
+const (
+	StateUnavailable = "unavailable"
+	StateUnknown     = "unknown"
 )
 
 type State struct {
@@ -0,0 +1,196 @@
+package hal
+
+import (
+	"context"
+	"slices"
+
+	"github.com/dansimau/hal/hassws"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+)
+
+// Notification is a message sent through a notify service.
+type Notification struct {
+	Title   string
+	Message string
+
+	// Tag identifies the notification on the device, so that a later
+	// notification with the same tag replaces it.
+	Tag string
+
+	// Actions are buttons shown with the notification, for the mobile app.
+	// When one is chosen, a mobile_app_notification_action event is fired with
+	// its action ID.
+	Actions []NotificationAction
+
+	// Data is extra, platform-specific data (e.g. "priority" or "image").
+	Data map[string]any
+}
+
+// NotificationAction is a button on an actionable notification.
+type NotificationAction struct {
+	// Action is the ID sent back when the action is chosen. Use IDs that are
+	// unique to the notification, so that replies are not mixed up with those
+	// to other notifications.
+	Action string `json:"action"`
+	Title  string `json:"title"`
+
+	// URI is opened when the action is chosen, instead of sending the action
+	// back. Optional.
+	URI string `json:"uri,omitempty"`
+}
+
+// NotificationActionEvent is the reply to an actionable notification.
+type NotificationActionEvent struct {
+	// Action is the ID of the chosen action.
+	Action string `json:"action"`
+
+	// ReplyText is the text entered, for actions that accept a reply.
+	ReplyText string `json:"reply_text"`
+
+	// Tag is the tag of the notification, if it had one.
+	Tag string `json:"tag"`
+}
+
+// Notifier sends notifications through a notify service, e.g.
+// "mobile_app_pixel_7", and receives replies to actionable notifications.
+type Notifier struct {
+	connection *Connection
+	service    string
+}
+
+func NewNotifier(connection *Connection, service string) *Notifier {
+	return &Notifier{
+		connection: connection,
+		service:    service,
+	}
+}
+
+// Send sends the notification.
+func (n *Notifier) Send(ctx context.Context, notification Notification) error {
+	data := map[string]any{}
+	for k, v := range notification.Data {
+		data[k] = v
+	}
+
+	if notification.Tag != "" {
+		data["tag"] = notification.Tag
+	}
+
+	if len(notification.Actions) > 0 {
+		data["actions"] = notification.Actions
+	}
+
+	serviceData := map[string]any{
+		"message": notification.Message,
+	}
+
+	if notification.Title != "" {
+		serviceData["title"] = notification.Title
+	}
+
+	if len(data) > 0 {
+		serviceData["data"] = data
+	}
+
+	logger.InfoContext(ctx, "Sending notification", "service", n.service, "title", notification.Title)
+
+	if _, err := n.connection.CallService(hassws.CallServiceRequest{
+		Type:    hassws.MessageTypeCallService,
+		Domain:  "notify",
+		Service: n.service,
+		Data:    serviceData,
+	}); err != nil {
+		logger.ErrorContext(ctx, "Error sending notification", "service", n.service, "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// OnAction registers a handler for replies to actionable notifications. If
+// action IDs are given, only replies with those IDs are delivered. The handler
+// is called serially with automations, so it must not block. It returns a
+// function that removes the handler.
+func (n *Notifier) OnAction(handler func(NotificationActionEvent), actionIDs ...string) (remove func()) {
+	return n.connection.OnEvent(homeassistant.EventTypeMobileAppNotificationAction, actionHandler(handler, actionIDs))
+}
+
+// actionHandler returns an event handler that decodes notification actions and
+// passes those with one of the given action IDs (or all, if none are given) to
+// handler.
+func actionHandler(handler func(NotificationActionEvent), actionIDs []string) func(homeassistant.Event) {
+	return func(event homeassistant.Event) {
+		var action NotificationActionEvent
+		if err := event.EventData.Decode(&action); err != nil {
+			logger.Error("Error decoding notification action", "", "error", err)
+
+			return
+		}
+
+		if len(actionIDs) > 0 && !slices.Contains(actionIDs, action.Action) {
+			return
+		}
+
+		handler(action)
+	}
+}
+
+// WaitForAction blocks until a reply with one of the given action IDs (or any
+// action, if none are given) is received, or the context is done. It can be
+// called from an automation's Action, but no other automations run while it
+// waits.
+func (n *Notifier) WaitForAction(ctx context.Context, actionIDs ...string) (NotificationActionEvent, error) {
+	replies, remove := n.listenForReply(actionIDs)
+	defer remove()
+
+	return n.waitForReply(ctx, replies)
+}
+
+// SendAndWait sends an actionable notification and waits for one of its
+// actions to be chosen, or for the context to be done. Like WaitForAction, it
+// can be called from an automation's Action.
+func (n *Notifier) SendAndWait(ctx context.Context, notification Notification) (NotificationActionEvent, error) {
+	actionIDs := make([]string, 0, len(notification.Actions))
+	for _, action := range notification.Actions {
+		actionIDs = append(actionIDs, action.Action)
+	}
+
+	// Listen before sending, so that a quick reply is not missed.
+	replies, remove := n.listenForReply(actionIDs)
+	defer remove()
+
+	if err := n.Send(ctx, notification); err != nil {
+		return NotificationActionEvent{}, err
+	}
+
+	return n.waitForReply(ctx, replies)
+}
+
+// listenForReply returns a channel that receives the first reply with one of
+// the given action IDs. Replies are received as soon as they arrive, so that
+// waiting for one does not depend on automations being dispatched.
+func (n *Notifier) listenForReply(actionIDs []string) (replies chan NotificationActionEvent, remove func()) {
+	replies = make(chan NotificationActionEvent, 1)
+
+	remove = n.connection.observeEvent(homeassistant.EventTypeMobileAppNotificationAction, actionHandler(func(action NotificationActionEvent) {
+		select {
+		case replies <- action:
+		default:
+		}
+	}, actionIDs))
+
+	return replies, remove
+}
+
+func (n *Notifier) waitForReply(ctx context.Context, replies chan NotificationActionEvent) (NotificationActionEvent, error) {
+	select {
+	case action := <-replies:
+		logger.InfoContext(ctx, "Notification action received", "service", n.service, "action", action.Action)
+
+		return action, nil
+	case <-ctx.Done():
+		return NotificationActionEvent{}, ctx.Err()
+	}
+}
@@ -0,0 +1,154 @@
+package hal_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/hassws"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"gotest.tools/v3/assert"
+)
+
+func sendNotificationAction(server *hassws.Server, action string) {
+	server.SendEvent(homeassistant.Event{
+		EventType: homeassistant.EventTypeMobileAppNotificationAction,
+		EventData: homeassistant.EventData{
+			Raw: json.RawMessage(`{"action":"` + action + `","tag":"garage"}`),
+		},
+	})
+}
+
+func TestNotifier_SendAndWait(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	notifier := hal.NewNotifier(conn, "mobile_app_pixel")
+
+	type result struct {
+		action hal.NotificationActionEvent
+		err    error
+	}
+
+	resultCh := make(chan result, 1)
+
+	go func() {
+		action, err := notifier.SendAndWait(context.Background(), hal.Notification{
+			Title:   "Garage open",
+			Message: "Close it?",
+			Tag:     "garage",
+			Actions: []hal.NotificationAction{
+				{Action: "CLOSE_GARAGE", Title: "Close"},
+				{Action: "IGNORE_GARAGE", Title: "Ignore"},
+			},
+		})
+		resultCh <- result{action, err}
+	}()
+
+	testutil.WaitFor(t, "verify notification sent", func() bool {
+		for _, msg := range server.MessagesReceived() {
+			if strings.Contains(string(msg), `"service":"mobile_app_pixel"`) &&
+				strings.Contains(string(msg), `"action":"CLOSE_GARAGE"`) {
+				return true
+			}
+		}
+
+		return false
+	}, func() {})
+
+	// Replies to other notifications are ignored.
+	sendNotificationAction(server, "UNLOCK_DOOR")
+	sendNotificationAction(server, "CLOSE_GARAGE")
+
+	select {
+	case r := <-resultCh:
+		assert.NilError(t, r.err)
+		assert.Equal(t, r.action.Action, "CLOSE_GARAGE")
+		assert.Equal(t, r.action.Tag, "garage")
+	case <-time.After(time.Second):
+		t.Fatal("SendAndWait did not return after the action was chosen")
+	}
+}
+
+func TestNotifier_SendAndWaitFromAutomation(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	notifier := hal.NewNotifier(conn, "mobile_app_pixel")
+
+	garageDoor := hal.NewBinarySensor("binary_sensor.garage_door")
+	conn.RegisterEntities(garageDoor)
+
+	type result struct {
+		action hal.NotificationActionEvent
+		err    error
+	}
+
+	resultCh := make(chan result, 1)
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("garage.ask_to_close").
+			WithEntities(garageDoor).
+			WithAction(func(ctx context.Context, _ hal.EntityInterface) {
+				ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+				defer cancel()
+
+				action, err := notifier.SendAndWait(ctx, hal.Notification{
+					Message: "Close the garage?",
+					Actions: []hal.NotificationAction{{Action: "CLOSE_GARAGE", Title: "Close"}},
+				})
+				resultCh <- result{action, err}
+			}),
+	)
+
+	server.SendEvent(homeassistant.Event{
+		EventData: homeassistant.EventData{
+			EntityID: garageDoor.GetID(),
+			NewState: &homeassistant.State{EntityID: garageDoor.GetID(), State: "on"},
+		},
+	})
+
+	testutil.WaitFor(t, "verify notification sent", func() bool {
+		for _, msg := range server.MessagesReceived() {
+			if strings.Contains(string(msg), `"action":"CLOSE_GARAGE"`) {
+				return true
+			}
+		}
+
+		return false
+	}, func() {})
+
+	sendNotificationAction(server, "CLOSE_GARAGE")
+
+	select {
+	case r := <-resultCh:
+		assert.NilError(t, r.err)
+		assert.Equal(t, r.action.Action, "CLOSE_GARAGE")
+	case <-time.After(3 * time.Second):
+		t.Fatal("SendAndWait did not return after the action was chosen")
+	}
+}
+
+func TestNotifier_WaitForActionTimeout(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	notifier := hal.NewNotifier(conn, "mobile_app_pixel")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := notifier.WaitForAction(ctx, "CLOSE_GARAGE")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
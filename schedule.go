@@ -0,0 +1,336 @@
+package hal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule decides when a scheduled automation runs. Schedules work in wall
+// clock time in the time zone of the time they are given, so a schedule at
+// 06:45 runs at 06:45 local time on both sides of a daylight saving change,
+// and times that repeat when the clocks go back run once.
+type Schedule interface {
+	// Next returns the first time strictly after the given time that the
+	// automation should run, or the zero time if it never runs again.
+	Next(after time.Time) time.Time
+}
+
+// atSchedule runs at a fixed time of day.
+type atSchedule struct {
+	hour, minute int
+	weekdays     []time.Weekday
+}
+
+// At returns a schedule that runs every day at the given time of day, or only
+// on the given weekdays if any are passed. If the time does not exist on a day
+// because the clocks go forward, it runs when the clocks have gone forward
+// (e.g. 02:30 runs at 03:30). The hour must be 0-23 and the minute 0-59.
+func At(hour, minute int, weekdays ...time.Weekday) (Schedule, error) {
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return nil, fmt.Errorf("%w: time of day %02d:%02d out of range", ErrInvalidSchedule, hour, minute)
+	}
+
+	for _, weekday := range weekdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return nil, fmt.Errorf("%w: invalid weekday %d", ErrInvalidSchedule, weekday)
+		}
+	}
+
+	return atSchedule{hour: hour, minute: minute, weekdays: weekdays}, nil
+}
+
+// MustAt is like At but panics if the time is invalid. It is intended for
+// times that are constants.
+func MustAt(hour, minute int, weekdays ...time.Weekday) Schedule {
+	s, err := At(hour, minute, weekdays...)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// Daily is the same as MustAt with no weekdays.
+func Daily(hour, minute int) Schedule {
+	return MustAt(hour, minute)
+}
+
+// Weekdays returns the days Monday to Friday, for use with At and MustAt.
+func Weekdays() []time.Weekday {
+	return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+}
+
+func (s atSchedule) Next(after time.Time) time.Time {
+	year, month, day := after.Date()
+
+	// A week and a day covers every weekday, even if the first candidate is
+	// earlier in the day than after.
+	for i := 0; i <= 7; i++ {
+		next := time.Date(year, month, day+i, s.hour, s.minute, 0, 0, after.Location())
+
+		// time.Date may return a time before the clocks went forward for a
+		// time that does not exist; move it forward by the same amount.
+		if next.Hour() != s.hour || next.Minute() != s.minute {
+			_, offsetBefore := next.Zone()
+			_, offsetAfter := next.Add(2 * time.Hour).Zone()
+			next = next.Add(time.Duration(offsetAfter-offsetBefore) * time.Second)
+		}
+
+		if !next.After(after) || !wallClock(next).After(wallClock(after)) {
+			continue
+		}
+
+		if len(s.weekdays) > 0 && !slices.Contains(s.weekdays, next.Weekday()) {
+			continue
+		}
+
+		return next
+	}
+
+	return time.Time{}
+}
+
+func (s atSchedule) String() string {
+	return fmt.Sprintf("at %02d:%02d", s.hour, s.minute)
+}
+
+// everySchedule runs at a fixed interval.
+type everySchedule struct {
+	interval time.Duration
+}
+
+// Every returns a schedule that runs at the given interval. Runs are aligned
+// to multiples of the interval from local midnight (e.g. every 5 minutes runs
+// at :00, :05, :10), so they do not drift when the process restarts. Every day
+// starts again at midnight, so intervals should divide a day evenly; intervals
+// longer than a day are aligned to multiples of the interval since the Unix
+// epoch instead.
+func Every(interval time.Duration) Schedule {
+	return everySchedule{interval: interval}
+}
+
+func (s everySchedule) Next(after time.Time) time.Time {
+	if s.interval <= 0 {
+		return time.Time{}
+	}
+
+	if s.interval > 24*time.Hour {
+		return after.Truncate(s.interval).Add(s.interval)
+	}
+
+	year, month, day := after.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, after.Location())
+	nextMidnight := time.Date(year, month, day+1, 0, 0, 0, 0, after.Location())
+
+	next := midnight.Add((after.Sub(midnight)/s.interval + 1) * s.interval)
+
+	// Days are shorter or longer than 24 hours when daylight saving time
+	// starts or ends.
+	if !next.Before(nextMidnight) {
+		return nextMidnight
+	}
+
+	return next
+}
+
+func (s everySchedule) String() string {
+	return "every " + s.interval.String()
+}
+
+// cronField is the set of values a cron field matches.
+type cronField struct {
+	values [61]bool
+	any    bool // true if the field is "*"
+}
+
+func (f *cronField) matches(value int) bool {
+	return f.values[value]
+}
+
+// cronSchedule runs at the times matched by a cron expression.
+type cronSchedule struct {
+	expression string
+
+	minute, hour, dayOfMonth, month, dayOfWeek cronField
+}
+
+// Cron returns a schedule for a standard five-field cron expression: minute,
+// hour, day of month, month and day of week (0 or 7 is Sunday). Fields may be
+// "*", a number, a range ("1-5"), a step ("*/15" or "0-30/10") or a list of
+// these ("1,15"). As in cron, if both day of month and day of week are
+// restricted, a day matching either runs. Times that do not exist because the
+// clocks go forward are skipped.
+func Cron(expression string) (Schedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: cron expression %q must have 5 fields", ErrInvalidSchedule, expression)
+	}
+
+	s := &cronSchedule{expression: expression}
+
+	for _, f := range []struct {
+		field    *cronField
+		value    string
+		min, max int
+	}{
+		{&s.minute, fields[0], 0, 59},
+		{&s.hour, fields[1], 0, 23},
+		{&s.dayOfMonth, fields[2], 1, 31},
+		{&s.month, fields[3], 1, 12},
+		{&s.dayOfWeek, fields[4], 0, 7},
+	} {
+		if err := parseCronField(f.field, f.value, f.min, f.max); err != nil {
+			return nil, fmt.Errorf("%w: cron expression %q: %w", ErrInvalidSchedule, expression, err)
+		}
+	}
+
+	// Sunday can be written as 0 or 7.
+	if s.dayOfWeek.values[7] {
+		s.dayOfWeek.values[0] = true
+	}
+
+	return s, nil
+}
+
+// MustCron is like Cron but panics if the expression is invalid. It is
+// intended for expressions that are constants.
+func MustCron(expression string) Schedule {
+	s, err := Cron(expression)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func parseCronField(field *cronField, value string, minValue, maxValue int) error {
+	field.any = value == "*"
+
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid step %q", part)
+			}
+
+			step = n
+		}
+
+		low, high := minValue, maxValue
+
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+
+			n, err := strconv.Atoi(lowPart)
+			if err != nil {
+				return fmt.Errorf("invalid value %q", part)
+			}
+
+			low, high = n, n
+
+			if isRange {
+				if high, err = strconv.Atoi(highPart); err != nil {
+					return fmt.Errorf("invalid range %q", part)
+				}
+			} else if hasStep {
+				high = maxValue
+			}
+		}
+
+		if low < minValue || high > maxValue || low > high {
+			return fmt.Errorf("value %q out of range %d-%d", part, minValue, maxValue)
+		}
+
+		for i := low; i <= high; i += step {
+			field.values[i] = true
+		}
+	}
+
+	return nil
+}
+
+// cronSearchLimit bounds the search for the next run, so that expressions
+// that never match (e.g. 30 February) do not loop forever.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronSearchLimit)
+
+	// Fields are advanced in wall clock time with time.Date, which keeps runs
+	// at the same local time across daylight saving changes.
+	for t.Before(limit) {
+		year, month, day := t.Date()
+
+		if !s.month.matches(int(month)) {
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+
+			continue
+		}
+
+		if !s.hour.matches(t.Hour()) {
+			next := time.Date(year, month, day, t.Hour()+1, 0, 0, 0, loc)
+
+			// The next hour does not exist when the clocks go forward.
+			if !next.After(t) {
+				next = t.Add(time.Hour).Truncate(time.Hour)
+			}
+
+			t = next
+
+			continue
+		}
+
+		// Skip times that repeat when the clocks go back, so that they run
+		// once.
+		if !s.minute.matches(t.Minute()) || !wallClock(t).After(wallClock(after)) {
+			t = t.Add(time.Minute)
+
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth.matches(t.Day())
+	dayOfWeek := s.dayOfWeek.matches(int(t.Weekday()))
+
+	if s.dayOfMonth.any || s.dayOfWeek.any {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
+
+func (s *cronSchedule) String() string {
+	return "cron " + s.expression
+}
+
+// wallClock returns the local time shown on a clock in t's time zone, as a
+// time in UTC, for comparing times regardless of daylight saving.
+func wallClock(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
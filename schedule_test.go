@@ -0,0 +1,297 @@
+package hal_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	assert.NilError(t, err)
+
+	return loc
+}
+
+func TestAt(t *testing.T) {
+	t.Parallel()
+
+	loc := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		schedule hal.Schedule
+		after    time.Time
+		want     time.Time
+	}{
+		{
+			name:     "later today",
+			schedule: hal.MustAt(6, 45),
+			after:    time.Date(2026, 3, 2, 6, 0, 0, 0, loc),
+			want:     time.Date(2026, 3, 2, 6, 45, 0, 0, loc),
+		},
+		{
+			name:     "tomorrow when the time has passed",
+			schedule: hal.Daily(6, 45),
+			after:    time.Date(2026, 3, 2, 6, 45, 0, 0, loc),
+			want:     time.Date(2026, 3, 3, 6, 45, 0, 0, loc),
+		},
+		{
+			name:     "weekdays skip the weekend",
+			schedule: hal.MustAt(6, 45, hal.Weekdays()...),
+			after:    time.Date(2026, 3, 6, 7, 0, 0, 0, loc), // Friday
+			want:     time.Date(2026, 3, 9, 6, 45, 0, 0, loc),
+		},
+		{
+			name:     "same local time after the clocks go forward",
+			schedule: hal.MustAt(6, 45),
+			after:    time.Date(2026, 3, 7, 7, 0, 0, 0, loc),
+			want:     time.Date(2026, 3, 8, 6, 45, 0, 0, loc),
+		},
+		{
+			name:     "time skipped when the clocks go forward",
+			schedule: hal.MustAt(2, 30),
+			after:    time.Date(2026, 3, 8, 0, 0, 0, 0, loc),
+			want:     time.Date(2026, 3, 8, 3, 30, 0, 0, loc),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tc.schedule.Next(tc.after)
+			assert.Assert(t, got.Equal(tc.want), "got %s, want %s", got, tc.want)
+		})
+	}
+
+	// The elapsed time across the change is an hour less than a day.
+	first := hal.MustAt(6, 45).Next(time.Date(2026, 3, 7, 0, 0, 0, 0, loc))
+	second := hal.MustAt(6, 45).Next(first)
+	assert.Equal(t, second.Sub(first), 23*time.Hour)
+}
+
+func TestAt_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		hour, minute int
+		weekdays     []time.Weekday
+	}{
+		{hour: 24, minute: 0},
+		{hour: -1, minute: 0},
+		{hour: 7, minute: 60},
+		{hour: 7, minute: -5},
+		{hour: 7, minute: 0, weekdays: []time.Weekday{7}},
+	} {
+		_, err := hal.At(tc.hour, tc.minute, tc.weekdays...)
+		assert.Assert(t, errors.Is(err, hal.ErrInvalidSchedule), "%02d:%02d %v", tc.hour, tc.minute, tc.weekdays)
+	}
+
+	assert.Assert(t, func() (panicked bool) {
+		defer func() { panicked = recover() != nil }()
+
+		hal.MustAt(25, 0)
+
+		return false
+	}())
+}
+
+func TestEvery(t *testing.T) {
+	t.Parallel()
+
+	schedule := hal.Every(5 * time.Minute)
+
+	got := schedule.Next(time.Date(2026, 3, 2, 6, 3, 20, 0, time.UTC))
+	assert.Equal(t, got, time.Date(2026, 3, 2, 6, 5, 0, 0, time.UTC))
+
+	got = schedule.Next(got)
+	assert.Equal(t, got, time.Date(2026, 3, 2, 6, 10, 0, 0, time.UTC))
+
+	assert.Assert(t, hal.Every(0).Next(got).IsZero())
+
+	// Aligned to local time, not UTC.
+	kolkata := mustLoadLocation(t, "Asia/Kolkata")
+
+	got = hal.Every(time.Hour).Next(time.Date(2026, 3, 2, 6, 10, 0, 0, kolkata))
+	assert.Assert(t, got.Equal(time.Date(2026, 3, 2, 7, 0, 0, 0, kolkata)), got)
+
+	// Daily runs are at local midnight, including across the change to
+	// daylight saving time.
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	got = hal.Every(24 * time.Hour).Next(time.Date(2026, 3, 7, 12, 0, 0, 0, newYork))
+	assert.Assert(t, got.Equal(time.Date(2026, 3, 8, 0, 0, 0, 0, newYork)), got)
+
+	got = hal.Every(24 * time.Hour).Next(got)
+	assert.Assert(t, got.Equal(time.Date(2026, 3, 9, 0, 0, 0, 0, newYork)), got)
+}
+
+func TestCron(t *testing.T) {
+	t.Parallel()
+
+	loc := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		expression string
+		after      time.Time
+		want       time.Time
+	}{
+		{
+			expression: "45 6 * * 1-5",
+			after:      time.Date(2026, 3, 6, 7, 0, 0, 0, loc), // Friday
+			want:       time.Date(2026, 3, 9, 6, 45, 0, 0, loc),
+		},
+		{
+			expression: "*/15 * * * *",
+			after:      time.Date(2026, 3, 2, 6, 50, 0, 0, loc),
+			want:       time.Date(2026, 3, 2, 7, 0, 0, 0, loc),
+		},
+		{
+			expression: "0 9,17 * * *",
+			after:      time.Date(2026, 3, 2, 9, 0, 0, 0, loc),
+			want:       time.Date(2026, 3, 2, 17, 0, 0, 0, loc),
+		},
+		{
+			// Day of month or day of week: the 13th, or any Friday.
+			expression: "0 0 13 * 5",
+			after:      time.Date(2026, 3, 1, 0, 0, 0, 0, loc),
+			want:       time.Date(2026, 3, 6, 0, 0, 0, 0, loc),
+		},
+		{
+			expression: "0 12 1 1 *",
+			after:      time.Date(2026, 3, 2, 0, 0, 0, 0, loc),
+			want:       time.Date(2027, 1, 1, 12, 0, 0, 0, loc),
+		},
+		{
+			expression: "0 12 * * 7",
+			after:      time.Date(2026, 3, 2, 0, 0, 0, 0, loc),
+			want:       time.Date(2026, 3, 8, 12, 0, 0, 0, loc),
+		},
+		{
+			// 02:30 does not exist on the day the clocks go forward.
+			expression: "30 2 * * *",
+			after:      time.Date(2026, 3, 7, 12, 0, 0, 0, loc),
+			want:       time.Date(2026, 3, 9, 2, 30, 0, 0, loc),
+		},
+		{
+			// 01:30 happens twice on the day the clocks go back, but runs once.
+			expression: "30 1 * * *",
+			after:      time.Date(2026, 11, 1, 1, 30, 0, 0, loc),
+			want:       time.Date(2026, 11, 2, 1, 30, 0, 0, loc),
+		},
+		{
+			expression: "0 0 30 2 *",
+			after:      time.Date(2026, 3, 2, 0, 0, 0, 0, loc),
+			want:       time.Time{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expression, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := hal.Cron(tc.expression)
+			assert.NilError(t, err)
+
+			got := schedule.Next(tc.after)
+			assert.Assert(t, got.Equal(tc.want), "got %s, want %s", got, tc.want)
+		})
+	}
+}
+
+func TestCron_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, expression := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		_, err := hal.Cron(expression)
+		assert.Assert(t, errors.Is(err, hal.ErrInvalidSchedule), "expression %q", expression)
+	}
+}
+
+func TestScheduledAutomation(t *testing.T) {
+	t.Parallel()
+
+	conn, _, cleanup := testutil.NewClientServerWithConfig(t, hal.Config{
+		TimeZone: "America/New_York",
+	})
+	defer cleanup()
+
+	loc := mustLoadLocation(t, "America/New_York")
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2026, 3, 6, 12, 0, 0, 0, loc)) // Friday
+	conn.WithClock(mockClock)
+
+	var (
+		mutex sync.Mutex
+		runs  []time.Time
+	)
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("wake.up").
+			WithSchedule(hal.MustAt(6, 45, hal.Weekdays()...)).
+			WithAction(func(context.Context, hal.EntityInterface) {
+				t.Error("Action called for a scheduled run")
+			}).
+			WithScheduledAction(func(ctx context.Context, scheduledTime time.Time) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				fromContext, ok := hal.GetScheduledTimeFromContext(ctx)
+				assert.Assert(t, ok)
+				assert.Assert(t, fromContext.Equal(scheduledTime))
+				assert.Equal(t, hal.GetAutomationNameFromContext(ctx), "wake.up")
+
+				runs = append(runs, scheduledTime)
+			}),
+	)
+
+	// Timers fire asynchronously, so the clock is moved past one run at a time.
+	advanceTo := func(now time.Time, wantRuns int) {
+		t.Helper()
+
+		mockClock.Set(now)
+
+		testutil.WaitFor(t, "verify scheduled runs", func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			return len(runs) == wantRuns
+		}, func() {
+			spew.Dump(runs)
+		})
+	}
+
+	// Over the weekend and the change to daylight saving time.
+	advanceTo(time.Date(2026, 3, 9, 7, 0, 0, 0, loc), 1)
+	advanceTo(time.Date(2026, 3, 10, 7, 0, 0, 0, loc), 2)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	assert.Assert(t, runs[0].Equal(time.Date(2026, 3, 9, 6, 45, 0, 0, loc)))
+	assert.Assert(t, runs[1].Equal(time.Date(2026, 3, 10, 6, 45, 0, 0, loc)))
+}
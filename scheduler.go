@@ -0,0 +1,132 @@
+package hal
+
+import (
+	"context"
+	"time"
+
+	"github.com/dansimau/hal/logger"
+	"github.com/dansimau/hal/store"
+)
+
+// ScheduledAutomation is an automation that also runs on schedules. Scheduled
+// runs call ScheduledAction instead of Action, as they have no trigger entity.
+type ScheduledAutomation interface {
+	Automation
+
+	Schedules() []Schedule
+
+	// ScheduledAction is called for each scheduled run, with the time it was
+	// scheduled for.
+	ScheduledAction(ctx context.Context, scheduledTime time.Time)
+}
+
+// scheduledRun is a schedule registered for an automation. All fields are
+// guarded by the connection mutex.
+type scheduledRun struct {
+	automation ScheduledAutomation
+	schedule   Schedule
+	timer      *Timer
+	next       time.Time
+}
+
+// registerSchedules adds the automation's schedules, and starts them if the
+// connection has already been started.
+func (h *Connection) registerSchedules(automation ScheduledAutomation) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, schedule := range automation.Schedules() {
+		// Sun schedules are computed for the configured location unless they
+		// were given one.
+		if sun, ok := schedule.(*SunSchedule); ok && sun.location == nil {
+			located := *sun
+			located.location = &h.config.Location
+			schedule = &located
+		}
+
+		run := &scheduledRun{automation: automation, schedule: schedule}
+		h.schedules = append(h.schedules, run)
+
+		if h.schedulesStarted {
+			h.scheduleNext(run)
+		}
+	}
+}
+
+// startSchedules starts the timers for all registered schedules.
+func (h *Connection) startSchedules() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.schedulesStarted {
+		return
+	}
+
+	h.schedulesStarted = true
+
+	for _, run := range h.schedules {
+		h.scheduleNext(run)
+	}
+}
+
+// stopSchedules cancels the timers for all registered schedules.
+func (h *Connection) stopSchedules() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.schedulesStarted = false
+
+	for _, run := range h.schedules {
+		if run.timer != nil {
+			run.timer.Cancel()
+		}
+	}
+}
+
+// scheduleNext starts the timer for the next run of a schedule. The caller
+// must hold mutex.
+func (h *Connection) scheduleNext(run *scheduledRun) {
+	now := h.getClock().Now().In(h.location)
+
+	next := run.schedule.Next(now)
+	if next.IsZero() {
+		logger.Info("Schedule has no more runs", "", "automation", run.automation.Name())
+
+		return
+	}
+
+	if run.timer == nil {
+		run.timer = NewTimer(h.getClock())
+	}
+
+	run.next = next
+
+	logger.Debug("Scheduling automation", "", "automation", run.automation.Name(), "next", next)
+
+	run.timer.StartContext(context.Background(), func(context.Context) {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+
+		if !h.schedulesStarted {
+			return
+		}
+
+		// Schedule the next run before this one's action, so that it is
+		// computed from when this run was due rather than when it finished.
+		h.scheduleNext(run)
+		h.runScheduledAutomation(run.automation, next)
+	}, next.Sub(now))
+}
+
+// runScheduledAutomation runs an automation for a scheduled time. The caller
+// must hold mutex.
+func (h *Connection) runScheduledAutomation(automation ScheduledAutomation, scheduledTime time.Time) {
+	// Create context with tracing metadata
+	ctx := NewAutomationContext("", automation.Name())
+	ctx = WithScheduledTime(ctx, scheduledTime)
+
+	logger.InfoContext(ctx, "Running scheduled automation", "scheduled_time", scheduledTime)
+	// Record automation triggered metric
+	h.metricsService.RecordCounter(store.MetricTypeAutomationTriggered, "", automation.Name())
+	automation.ScheduledAction(ctx, scheduledTime)
+}
@@ -65,3 +65,16 @@ type Log struct {
 	EntityID  string    `gorm:"index;size:255"`         // Optional: which entity this log relates to
 	LogText   string    `gorm:"not null;type:text"`
 }
+
+// HealthFinding is a problem with an entity found by the watchdog, e.g. a
+// sensor that has stopped reporting. The table holds the findings from the
+// latest check; an entity's rows are removed once it recovers.
+type HealthFinding struct {
+	EntityID     string    `gorm:"primaryKey;size:255"`
+	Kind         string    `gorm:"primaryKey;size:50"` // stale or low_battery
+	Message      string    `gorm:"not null"`
+	LastReported time.Time // When the entity last reported to Home Assistant
+	BatteryLevel *float64  // Optional: battery percentage, if reported
+	Since        time.Time `gorm:"not null"` // When the problem was first found
+	CheckedAt    time.Time `gorm:"not null"` // When the latest check ran
+}
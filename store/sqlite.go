@@ -45,7 +45,7 @@ func Open(path string) (*Store, error) {
 		return nil, err
 	}
 
-	if err := db.AutoMigrate(&Entity{}, &Metric{}, &MetricRollup{}, &Log{}); err != nil {
+	if err := db.AutoMigrate(&Entity{}, &Metric{}, &MetricRollup{}, &Log{}, &HealthFinding{}); err != nil {
 		return nil, err
 	}
 
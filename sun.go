@@ -1,18 +1,25 @@
 package hal
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/dansimau/hal/logger"
 	"github.com/nathan-osman/go-sunrise"
 )
 
+// civilTwilightElevation is the sun elevation at dawn and dusk, in degrees.
+const civilTwilightElevation = -6
+
 type SunTimes struct {
+	clock    clock.Clock
 	location LocationConfig
 }
 
 func NewSunTimes(cfg LocationConfig) *SunTimes {
 	sunTimes := &SunTimes{
+		clock:    clock.New(),
 		location: cfg,
 	}
 
@@ -26,8 +33,15 @@ func NewSunTimes(cfg LocationConfig) *SunTimes {
 	return sunTimes
 }
 
+// WithClock can be used to pass in a mock clock for testing.
+func (s *SunTimes) WithClock(c clock.Clock) *SunTimes {
+	s.clock = c
+
+	return s
+}
+
 func (s *SunTimes) IsDayTime() bool {
-	now := time.Now()
+	now := s.clock.Now()
 
 	rise, set := sunrise.SunriseSunset(s.location.Latitude, s.location.Longitude, now.Year(), now.Month(), now.Day())
 
@@ -39,7 +53,7 @@ func (s *SunTimes) IsNightTime() bool {
 }
 
 func (s *SunTimes) Sunrise() time.Time {
-	now := time.Now()
+	now := s.clock.Now()
 
 	rise, _ := sunrise.SunriseSunset(s.location.Latitude, s.location.Longitude, now.Year(), now.Month(), now.Day())
 
@@ -47,9 +61,166 @@ func (s *SunTimes) Sunrise() time.Time {
 }
 
 func (s *SunTimes) Sunset() time.Time {
-	now := time.Now()
+	now := s.clock.Now()
 
 	_, set := sunrise.SunriseSunset(s.location.Latitude, s.location.Longitude, now.Year(), now.Month(), now.Day())
 
 	return set
 }
+
+// Dawn returns the start of civil twilight today, when the sun is 6° below
+// the horizon.
+func (s *SunTimes) Dawn() time.Time {
+	now := s.clock.Now()
+
+	dawn, _ := sunrise.TimeOfElevation(s.location.Latitude, s.location.Longitude, civilTwilightElevation, now.Year(), now.Month(), now.Day())
+
+	return dawn
+}
+
+// Dusk returns the end of civil twilight today, when the sun is 6° below the
+// horizon.
+func (s *SunTimes) Dusk() time.Time {
+	now := s.clock.Now()
+
+	_, dusk := sunrise.TimeOfElevation(s.location.Latitude, s.location.Longitude, civilTwilightElevation, now.Year(), now.Month(), now.Day())
+
+	return dusk
+}
+
+// Elevation returns the current angle of the sun above the horizon, in
+// degrees. It is negative when the sun is below the horizon.
+func (s *SunTimes) Elevation() float64 {
+	return sunrise.Elevation(s.location.Latitude, s.location.Longitude, s.clock.Now())
+}
+
+// SunEvent is a daily event in the sun's path across the sky.
+type SunEvent string
+
+const (
+	SunEventSunrise SunEvent = "sunrise"
+	SunEventSunset  SunEvent = "sunset"
+	SunEventDawn    SunEvent = "dawn"
+	SunEventDusk    SunEvent = "dusk"
+)
+
+// SunSchedule is a Schedule that runs at a sun event or when the sun crosses
+// an elevation, computed for each day from the location. Schedules registered
+// with a connection use the location from the config unless one is set with
+// WithLocation.
+type SunSchedule struct {
+	event     SunEvent // empty for elevation crossings
+	elevation float64
+	rising    bool
+	offset    time.Duration
+	location  *LocationConfig
+}
+
+// AtSunrise returns a schedule that runs at sunrise, moved by offset (e.g.
+// -30*time.Minute for half an hour before).
+func AtSunrise(offset time.Duration) *SunSchedule {
+	return &SunSchedule{event: SunEventSunrise, rising: true, offset: offset}
+}
+
+// AtSunset returns a schedule that runs at sunset, moved by offset.
+func AtSunset(offset time.Duration) *SunSchedule {
+	return &SunSchedule{event: SunEventSunset, offset: offset}
+}
+
+// AtDawn returns a schedule that runs at dawn (civil twilight), moved by
+// offset.
+func AtDawn(offset time.Duration) *SunSchedule {
+	return &SunSchedule{event: SunEventDawn, elevation: civilTwilightElevation, rising: true, offset: offset}
+}
+
+// AtDusk returns a schedule that runs at dusk (civil twilight), moved by
+// offset.
+func AtDusk(offset time.Duration) *SunSchedule {
+	return &SunSchedule{event: SunEventDusk, elevation: civilTwilightElevation, offset: offset}
+}
+
+// WhenSunRisesAbove returns a schedule that runs in the morning when the sun's
+// elevation rises above the given number of degrees.
+func WhenSunRisesAbove(elevation float64) *SunSchedule {
+	return &SunSchedule{elevation: elevation, rising: true}
+}
+
+// WhenSunDropsBelow returns a schedule that runs in the evening when the sun's
+// elevation drops below the given number of degrees.
+func WhenSunDropsBelow(elevation float64) *SunSchedule {
+	return &SunSchedule{elevation: elevation}
+}
+
+// WithLocation sets the location the schedule is computed for.
+func (s *SunSchedule) WithLocation(location LocationConfig) *SunSchedule {
+	s.location = &location
+
+	return s
+}
+
+// sunSearchDays bounds the search for the next run. Near the poles the sun
+// may not rise or set for months.
+const sunSearchDays = 366
+
+// Next returns the time of the event on the first day it happens after the
+// given time. The time is recomputed for each day, as sun events move through
+// the year. It returns the zero time if no location is set.
+func (s *SunSchedule) Next(after time.Time) time.Time {
+	if s.location == nil {
+		return time.Time{}
+	}
+
+	year, month, day := after.Date()
+
+	// Start from the day before, since an event with a large offset can fall
+	// on the next day.
+	for i := -1; i <= sunSearchDays; i++ {
+		date := time.Date(year, month, day+i, 12, 0, 0, 0, after.Location())
+
+		event := s.timeOn(date)
+		if event.IsZero() {
+			continue
+		}
+
+		if next := event.Add(s.offset).In(after.Location()); next.After(after) {
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+// timeOn returns the time of the event on the given date, or the zero time if
+// it does not happen that day.
+func (s *SunSchedule) timeOn(date time.Time) time.Time {
+	var morning, evening time.Time
+
+	// Sunrise and sunset match SunTimes, which allows for refraction.
+	if s.event == SunEventSunrise || s.event == SunEventSunset {
+		morning, evening = sunrise.SunriseSunset(s.location.Latitude, s.location.Longitude, date.Year(), date.Month(), date.Day())
+	} else {
+		morning, evening = sunrise.TimeOfElevation(s.location.Latitude, s.location.Longitude, s.elevation, date.Year(), date.Month(), date.Day())
+	}
+
+	if s.rising {
+		return morning
+	}
+
+	return evening
+}
+
+func (s *SunSchedule) String() string {
+	if s.event == "" {
+		if s.rising {
+			return fmt.Sprintf("sun elevation above %v°", s.elevation)
+		}
+
+		return fmt.Sprintf("sun elevation below %v°", s.elevation)
+	}
+
+	if s.offset == 0 {
+		return string(s.event)
+	}
+
+	return fmt.Sprintf("%s %+v", s.event, s.offset)
+}
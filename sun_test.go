@@ -1,9 +1,16 @@
 package hal_test
 
 import (
+	"context"
+	"math"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
 	"gotest.tools/v3/assert"
 )
 func TestSunTimes_Sunrise(t *testing.T) {
@@ -156,3 +163,147 @@ func TestSunTimes_EdgeCases(t *testing.T) {
 		assert.Assert(t, sunrise.Before(sunset))
 	})
 }
+
+func TestSunSchedule(t *testing.T) {
+	t.Parallel()
+
+	loc := mustLoadLocation(t, "America/Los_Angeles")
+	sanFrancisco := hal.LocationConfig{Latitude: 37.7749, Longitude: -122.4194}
+
+	noon := time.Date(2026, 6, 21, 12, 0, 0, 0, loc)
+
+	mockClock := clock.NewMock()
+	mockClock.Set(noon)
+
+	sunTimes := hal.NewSunTimes(sanFrancisco).WithClock(mockClock)
+
+	t.Run("offset from sunset", func(t *testing.T) {
+		t.Parallel()
+
+		got := hal.AtSunset(-30 * time.Minute).WithLocation(sanFrancisco).Next(noon)
+		assert.Assert(t, got.Equal(sunTimes.Sunset().Add(-30*time.Minute)), "got %s", got)
+		assert.Equal(t, got.Location(), loc)
+		assert.Equal(t, got.Format(time.Kitchen), "8:04PM")
+	})
+
+	t.Run("sunrise has passed today", func(t *testing.T) {
+		t.Parallel()
+
+		got := hal.AtSunrise(0).WithLocation(sanFrancisco).Next(noon)
+		assert.Assert(t, got.After(noon), "got %s", got)
+		assert.Equal(t, got.YearDay(), noon.YearDay()+1)
+		assert.Equal(t, got.Hour(), 5)
+	})
+
+	t.Run("dawn and dusk", func(t *testing.T) {
+		t.Parallel()
+
+		dawn := hal.AtDawn(0).WithLocation(sanFrancisco).Next(noon.Add(-12 * time.Hour))
+		assert.Assert(t, dawn.Equal(sunTimes.Dawn()), "got %s", dawn)
+		assert.Assert(t, dawn.Before(sunTimes.Sunrise()))
+
+		dusk := hal.AtDusk(0).WithLocation(sanFrancisco).Next(noon)
+		assert.Assert(t, dusk.Equal(sunTimes.Dusk()), "got %s", dusk)
+		assert.Assert(t, dusk.After(sunTimes.Sunset()))
+	})
+
+	t.Run("elevation crossings", func(t *testing.T) {
+		t.Parallel()
+
+		below := hal.WhenSunDropsBelow(3).WithLocation(sanFrancisco).Next(noon)
+		assert.Assert(t, below.After(noon) && below.Before(sunTimes.Sunset()), "got %s", below)
+		assert.Assert(t, math.Abs(hal.NewSunTimes(sanFrancisco).WithClock(mockAt(below)).Elevation()-3) < 0.1)
+
+		above := hal.WhenSunRisesAbove(3).WithLocation(sanFrancisco).Next(noon)
+		assert.Assert(t, above.After(below), "got %s", above)
+		assert.Assert(t, math.Abs(hal.NewSunTimes(sanFrancisco).WithClock(mockAt(above)).Elevation()-3) < 0.1)
+	})
+
+	t.Run("no location", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Assert(t, hal.AtSunset(0).Next(noon).IsZero())
+	})
+
+	t.Run("polar night", func(t *testing.T) {
+		t.Parallel()
+
+		svalbard := hal.LocationConfig{Latitude: 78.2156, Longitude: 15.5503}
+		midwinter := time.Date(2026, 12, 21, 12, 0, 0, 0, time.UTC)
+
+		// The sun does not rise again until February.
+		got := hal.AtSunrise(0).WithLocation(svalbard).Next(midwinter)
+		assert.Assert(t, got.Month() == time.February, "got %s", got)
+	})
+}
+
+func mockAt(t time.Time) clock.Clock {
+	mockClock := clock.NewMock()
+	mockClock.Set(t)
+
+	return mockClock
+}
+
+func TestSunScheduledAutomation(t *testing.T) {
+	t.Parallel()
+
+	sanFrancisco := hal.LocationConfig{Latitude: 37.7749, Longitude: -122.4194}
+
+	conn, _, cleanup := testutil.NewClientServerWithConfig(t, hal.Config{
+		Location: sanFrancisco,
+		TimeZone: "America/Los_Angeles",
+	})
+	defer cleanup()
+
+	loc := mustLoadLocation(t, "America/Los_Angeles")
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2026, 6, 21, 12, 0, 0, 0, loc))
+	conn.WithClock(mockClock)
+
+	var (
+		mutex sync.Mutex
+		runs  []time.Time
+	)
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("porch.light").
+			// Uses the location from the config.
+			WithSchedule(hal.AtSunset(-30 * time.Minute)).
+			WithScheduledAction(func(_ context.Context, scheduledTime time.Time) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				runs = append(runs, scheduledTime)
+			}),
+	)
+
+	// Timers fire asynchronously, so the clock is moved past one sunset at a
+	// time.
+	advanceTo := func(now time.Time, wantRuns int) {
+		t.Helper()
+
+		mockClock.Set(now)
+
+		testutil.WaitFor(t, "verify sun scheduled runs", func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			return len(runs) == wantRuns
+		}, func() {
+			spew.Dump(runs)
+		})
+	}
+
+	advanceTo(time.Date(2026, 6, 22, 0, 0, 0, 0, loc), 1)
+	advanceTo(time.Date(2026, 6, 23, 0, 0, 0, 0, loc), 2)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// Recomputed for each day.
+	assert.Assert(t, runs[0].Equal(hal.AtSunset(-30*time.Minute).WithLocation(sanFrancisco).Next(time.Date(2026, 6, 21, 12, 0, 0, 0, loc))))
+	assert.Assert(t, runs[1].Equal(hal.AtSunset(-30*time.Minute).WithLocation(sanFrancisco).Next(runs[0])))
+	assert.Assert(t, runs[1].Sub(runs[0]) != 24*time.Hour)
+}
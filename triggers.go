@@ -0,0 +1,171 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+)
+
+// StateTrigger runs an automation when an entity's state changes, optionally
+// only for particular transitions. Unlike WithEntities, changes to attributes
+// alone do not trigger it.
+//
+//	hal.StateTrigger{Entity: door, From: "off", To: "on", For: 5 * time.Minute}
+type StateTrigger struct {
+	Entity EntityInterface
+
+	// From and To restrict the trigger to changes from and to the given
+	// states. Either may be empty to match any state.
+	From string
+	To   string
+
+	// Attribute, if set, makes the trigger watch the given attribute instead
+	// of the state. From and To are then compared against the attribute's
+	// value formatted as a string (e.g. "255" for a brightness of 255).
+	Attribute string
+
+	// For, if set, requires the new value to be held for the given duration
+	// before the automation runs. The pending run is cancelled if the value
+	// changes in the meantime.
+	For time.Duration
+}
+
+// stateTrigger is a StateTrigger registered with an automation, along with any
+// pending For timer. All fields below are guarded by the connection mutex.
+type stateTrigger struct {
+	StateTrigger
+
+	timer      *Timer
+	pending    bool
+	held       string
+	generation int
+}
+
+// stateTriggerer is implemented by automations with state triggers, which the
+// connection evaluates before running the automation.
+type stateTriggerer interface {
+	stateTriggers() []*stateTrigger
+}
+
+// value returns the value of the state that the trigger watches.
+func (t *stateTrigger) value(state homeassistant.State) string {
+	if t.Attribute == "" {
+		return state.State
+	}
+
+	value, ok := state.Attributes[t.Attribute]
+	if !ok || value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
+
+// matches returns true if a change from oldState to newState satisfies the
+// trigger.
+func (t *stateTrigger) matches(oldState, newState homeassistant.State) bool {
+	oldValue, newValue := t.value(oldState), t.value(newState)
+
+	if oldValue == newValue {
+		return false
+	}
+
+	if t.From != "" && oldValue != t.From {
+		return false
+	}
+
+	if t.To != "" && newValue != t.To {
+		return false
+	}
+
+	return true
+}
+
+// cancel stops a pending For timer. The caller must hold the connection
+// mutex.
+func (t *stateTrigger) cancel() {
+	if !t.pending {
+		return
+	}
+
+	t.timer.Cancel()
+	t.pending = false
+	t.generation++
+}
+
+// evaluateStateTriggers evaluates the automation's state triggers for a change
+// to entity, and returns true if the automation should run now. Automations
+// with no triggers for the entity (e.g. ones registered with WithEntities)
+// always run. Triggers with a For duration start a timer instead, which runs
+// the automation when it expires. Changes made by hal itself can cancel a
+// pending timer but never start one. The caller must hold mutex.
+func (h *Connection) evaluateStateTriggers(automation Automation, entity EntityInterface, change StateChange, ownChange bool) bool {
+	triggerer, ok := automation.(stateTriggerer)
+	if !ok {
+		return true
+	}
+
+	hasTrigger := false
+	run := false
+
+	for _, trigger := range triggerer.stateTriggers() {
+		if trigger.Entity.GetID() != change.EntityID {
+			continue
+		}
+
+		hasTrigger = true
+
+		if ownChange || !trigger.matches(change.OldState, change.NewState) {
+			if trigger.pending && trigger.value(change.NewState) != trigger.held {
+				logger.Debug("Cancelling pending state trigger", change.EntityID, "automation", automation.Name())
+				trigger.cancel()
+			}
+
+			continue
+		}
+
+		if trigger.For == 0 {
+			run = true
+
+			continue
+		}
+
+		h.startStateTriggerTimer(automation, entity, trigger, change)
+	}
+
+	return run || !hasTrigger
+}
+
+// startStateTriggerTimer runs the automation once the trigger's value has been
+// held for its For duration. The automation is passed the state change that
+// started the timer. The caller must hold mutex.
+func (h *Connection) startStateTriggerTimer(automation Automation, entity EntityInterface, trigger *stateTrigger, change StateChange) {
+	if trigger.timer == nil {
+		trigger.timer = NewTimer(h.getClock())
+	}
+
+	trigger.generation++
+	generation := trigger.generation
+	trigger.pending = true
+	trigger.held = trigger.value(change.NewState)
+
+	logger.Debug("Starting state trigger timer", change.EntityID, "automation", automation.Name(), "for", trigger.For)
+
+	trigger.timer.StartContext(context.Background(), func(context.Context) {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+
+		// The timer may have expired while a state change that cancelled it
+		// held the mutex.
+		if !trigger.pending || trigger.generation != generation {
+			return
+		}
+
+		trigger.pending = false
+
+		h.runAutomation(automation, entity, change)
+	}, trigger.For)
+}
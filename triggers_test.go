@@ -0,0 +1,216 @@
+package hal_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/hassws"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// sendState sends a state change for the entity and waits for it to be
+// applied.
+func sendState(t *testing.T, server *hassws.Server, entity hal.EntityInterface, state homeassistant.State, fromHAL bool) {
+	t.Helper()
+
+	state.EntityID = entity.GetID()
+
+	event := homeassistant.Event{
+		EventData: homeassistant.EventData{
+			EntityID: entity.GetID(),
+			NewState: &state,
+		},
+	}
+
+	if fromHAL {
+		event.Context.UserID = testutil.TestUserID
+	}
+
+	server.SendEvent(event)
+
+	testutil.WaitFor(t, "verify state applied", func() bool {
+		got := entity.GetState()
+
+		return got.State == state.State && spew.Sdump(got.Attributes) == spew.Sdump(state.Attributes)
+	}, func() {
+		spew.Dump(entity.GetState(), state)
+	})
+}
+
+// waitForCount waits for an automation to have been triggered the given
+// number of times.
+func waitForCount(t *testing.T, count *atomic.Int32, want int32) {
+	t.Helper()
+
+	testutil.WaitFor(t, "verify automation trigger count", func() bool {
+		return count.Load() == want
+	}, func() {
+		spew.Dump(count.Load())
+	})
+}
+
+func TestStateTrigger_FromTo(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	door := hal.NewBinarySensor("binary_sensor.door")
+	conn.RegisterEntities(door)
+
+	var triggered atomic.Int32
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("door.opened").
+			WithTriggers(hal.StateTrigger{Entity: door, From: "off", To: "on"}).
+			WithAction(func(_ context.Context, _ hal.EntityInterface) {
+				triggered.Add(1)
+			}),
+	)
+
+	sendState(t, server, door, homeassistant.State{State: "off"}, false)
+	sendState(t, server, door, homeassistant.State{State: "on"}, false)
+	waitForCount(t, &triggered, 1)
+
+	// An attribute-only update is not a state change.
+	sendState(t, server, door, homeassistant.State{State: "on", Attributes: map[string]any{"battery": float64(90)}}, false)
+	sendState(t, server, door, homeassistant.State{State: "off"}, false)
+	waitForCount(t, &triggered, 1)
+
+	sendState(t, server, door, homeassistant.State{State: "on"}, false)
+	waitForCount(t, &triggered, 2)
+}
+
+func TestStateTrigger_Attribute(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	light := hal.NewLight("light.kitchen")
+	conn.RegisterEntities(light)
+
+	var triggered atomic.Int32
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("light.full_brightness").
+			WithTriggers(hal.StateTrigger{Entity: light, Attribute: "brightness", To: "255"}).
+			WithAction(func(_ context.Context, _ hal.EntityInterface) {
+				triggered.Add(1)
+			}),
+	)
+
+	sendState(t, server, light, homeassistant.State{State: "on", Attributes: map[string]any{"brightness": float64(100)}}, false)
+	sendState(t, server, light, homeassistant.State{State: "on", Attributes: map[string]any{"brightness": float64(255)}}, false)
+	waitForCount(t, &triggered, 1)
+
+	sendState(t, server, light, homeassistant.State{State: "on", Attributes: map[string]any{"brightness": float64(255), "color_mode": "rgb"}}, false)
+	waitForCount(t, &triggered, 1)
+}
+
+func TestStateTrigger_For(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	mockClock := clock.NewMock()
+	conn.WithClock(mockClock)
+
+	door := hal.NewBinarySensor("binary_sensor.door")
+	motion := hal.NewBinarySensor("binary_sensor.motion")
+	conn.RegisterEntities(door, motion)
+
+	var doorTriggered, motionTriggered atomic.Int32
+
+	conn.RegisterAutomations(
+		hal.NewAutomation().
+			WithName("door.left_open").
+			WithTriggers(hal.StateTrigger{Entity: door, To: "on", For: 5 * time.Minute}).
+			WithAction(func(_ context.Context, _ hal.EntityInterface) {
+				doorTriggered.Add(1)
+			}),
+		// Triggers and plain entities can be combined.
+		hal.NewAutomation().
+			WithName("motion").
+			WithEntities(motion).
+			WithTriggers(hal.StateTrigger{Entity: door, To: "on"}).
+			WithAction(func(_ context.Context, _ hal.EntityInterface) {
+				motionTriggered.Add(1)
+			}),
+	)
+
+	sendState(t, server, door, homeassistant.State{State: "off"}, false)
+
+	t.Run("cancelled if the state reverts", func(t *testing.T) {
+		sendState(t, server, door, homeassistant.State{State: "on"}, false)
+		mockClock.Add(4 * time.Minute)
+		sendState(t, server, door, homeassistant.State{State: "off"}, false)
+		mockClock.Add(time.Hour)
+
+		waitForCount(t, &doorTriggered, 0)
+	})
+
+	t.Run("cancelled if hal reverts the state", func(t *testing.T) {
+		sendState(t, server, door, homeassistant.State{State: "on"}, false)
+		sendState(t, server, door, homeassistant.State{State: "off"}, true)
+		mockClock.Add(time.Hour)
+
+		waitForCount(t, &doorTriggered, 0)
+	})
+
+	t.Run("runs once the state is held", func(t *testing.T) {
+		sendState(t, server, door, homeassistant.State{State: "on"}, false)
+
+		// Attribute updates do not reset the timer.
+		mockClock.Add(4 * time.Minute)
+		sendState(t, server, door, homeassistant.State{State: "on", Attributes: map[string]any{"battery": float64(90)}}, false)
+		mockClock.Add(time.Minute)
+
+		waitForCount(t, &doorTriggered, 1)
+	})
+
+	sendState(t, server, motion, homeassistant.State{State: "on"}, false)
+	sendState(t, server, motion, homeassistant.State{State: "on", Attributes: map[string]any{"illuminance": float64(3)}}, false)
+
+	// Three door openings and two motion changes.
+	waitForCount(t, &motionTriggered, 5)
+}
+
+func TestStateTrigger_WithoutAction(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServer(t)
+	defer cleanup()
+
+	door := hal.NewBinarySensor("binary_sensor.door")
+	conn.RegisterEntities(door)
+
+	var triggered atomic.Int32
+
+	conn.RegisterAutomations(
+		// Only has an action for scheduled runs.
+		hal.NewAutomation().
+			WithName("door.scheduled").
+			WithTriggers(hal.StateTrigger{Entity: door, To: "on"}).
+			WithSchedule(hal.Every(time.Hour)).
+			WithScheduledAction(func(context.Context, time.Time) {}),
+		hal.NewAutomation().
+			WithName("door.opened").
+			WithTriggers(hal.StateTrigger{Entity: door, To: "on"}).
+			WithAction(func(_ context.Context, _ hal.EntityInterface) {
+				triggered.Add(1)
+			}),
+	)
+
+	sendState(t, server, door, homeassistant.State{State: "on"}, false)
+	waitForCount(t, &triggered, 1)
+}
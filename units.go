@@ -0,0 +1,69 @@
+package hal
+
+// Units of measurement, as reported in the unit_of_measurement attribute.
+const (
+	UnitCelsius    = "°C"
+	UnitFahrenheit = "°F"
+	UnitKelvin     = "K"
+
+	UnitMilliwatt = "mW"
+	UnitWatt      = "W"
+	UnitKilowatt  = "kW"
+	UnitMegawatt  = "MW"
+)
+
+// powerUnitsInWatts maps power units to their size in watts.
+var powerUnitsInWatts = map[string]float64{
+	UnitMilliwatt: 0.001,
+	UnitWatt:      1,
+	UnitKilowatt:  1000,
+	UnitMegawatt:  1000000,
+}
+
+// convertUnit converts a value between temperature units or between power
+// units. The second return value is false if the units are unknown or measure
+// different things.
+func convertUnit(value float64, from, to string) (float64, bool) {
+	if from == to {
+		return value, true
+	}
+
+	if celsius, ok := toCelsius(value, from); ok {
+		return fromCelsius(celsius, to)
+	}
+
+	fromWatts, fromOK := powerUnitsInWatts[from]
+	toWatts, toOK := powerUnitsInWatts[to]
+
+	if fromOK && toOK {
+		return value * fromWatts / toWatts, true
+	}
+
+	return 0, false
+}
+
+func toCelsius(value float64, unit string) (float64, bool) {
+	switch unit {
+	case UnitCelsius:
+		return value, true
+	case UnitFahrenheit:
+		return (value - 32) * 5 / 9, true
+	case UnitKelvin:
+		return value - 273.15, true
+	}
+
+	return 0, false
+}
+
+func fromCelsius(value float64, unit string) (float64, bool) {
+	switch unit {
+	case UnitCelsius:
+		return value, true
+	case UnitFahrenheit:
+		return value*9/5 + 32, true
+	case UnitKelvin:
+		return value + 273.15, true
+	}
+
+	return 0, false
+}
@@ -1,8 +1,11 @@
 package hal
 
 import (
+	"encoding/json"
+	"math"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -45,3 +48,53 @@ func getStringOrStringSlice(value any) []string {
 
 	return []string{}
 }
+
+// getFloat converts a numeric attribute value to a float64. Home Assistant
+// attributes decoded from JSON are float64, but numeric strings are accepted
+// too since some integrations report numbers as strings.
+func getFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// getString returns the attribute value if it is a string, or an empty string.
+func getString(value any) string {
+	s, _ := value.(string)
+
+	return s
+}
+
+// earthRadiusMeters is the mean radius of the Earth.
+const earthRadiusMeters = 6371008.8
+
+// distanceMeters returns the great-circle distance between two coordinates
+// using the haversine formula.
+func distanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
@@ -121,3 +121,30 @@ func TestGetStringOrStringSliceAdditional(t *testing.T) {
 		assert.DeepEqual(t, result, []string{})
 	})
 }
+
+func TestGetFloat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  any
+		want   float64
+		wantOK bool
+	}{
+		{name: "float64", input: float64(21.5), want: 21.5, wantOK: true},
+		{name: "int", input: 3, want: 3, wantOK: true},
+		{name: "numeric string", input: "19.25", want: 19.25, wantOK: true},
+		{name: "non-numeric string", input: "unavailable", wantOK: false},
+		{name: "nil", input: nil, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := getFloat(tt.input)
+			assert.Equal(t, ok, tt.wantOK)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package hal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/logger"
+	"github.com/dansimau/hal/store"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultHealthCheckInterval    = 5 * time.Minute
+	defaultBatteryThresholdPct    = 20
+	batteryDeviceClass            = "battery"
+	batteryLevelAttribute         = "battery_level"
+	batteryLevelFallbackAttribute = "battery"
+)
+
+// HealthFindingKind is the kind of problem found by the watchdog.
+type HealthFindingKind string
+
+const (
+	// HealthFindingStale means the entity has not reported within its window.
+	HealthFindingStale HealthFindingKind = "stale"
+
+	// HealthFindingLowBattery means the entity's battery is below the
+	// threshold.
+	HealthFindingLowBattery HealthFindingKind = "low_battery"
+)
+
+// HealthFinding is a problem with an entity found by the watchdog.
+type HealthFinding struct {
+	EntityID string
+	Kind     HealthFindingKind
+	Message  string
+
+	// LastReported is when the entity last reported to Home Assistant, even if
+	// its state did not change.
+	LastReported time.Time
+
+	// BatteryLevel is the battery percentage, if the entity reports one.
+	BatteryLevel *float64
+
+	// Since is when the problem was first found.
+	Since time.Time
+}
+
+type healthFindingKey struct {
+	entityID string
+	kind     HealthFindingKind
+}
+
+type findingListener struct {
+	id      int
+	handler func(ctx context.Context, finding HealthFinding)
+}
+
+// Watchdog periodically checks registered entities and flags those that have
+// not reported within their window, or whose battery (the battery_level or
+// battery attribute, or the state of a battery sensor) is below a threshold.
+// Findings are recorded in the store and shown by `hal health`.
+type Watchdog struct {
+	connection *Connection
+
+	// mutex guards the fields below.
+	mutex            sync.Mutex
+	checkInterval    time.Duration
+	batteryThreshold float64
+	staleAfter       time.Duration
+	windows          map[string]time.Duration
+	listeners        []findingListener
+	nextListenerID   int
+	active           map[healthFindingKey]HealthFinding
+}
+
+func newWatchdog(connection *Connection, cfg HealthConfig) *Watchdog {
+	checkInterval := cfg.CheckInterval
+	if checkInterval == 0 {
+		checkInterval = defaultHealthCheckInterval
+	}
+
+	batteryThreshold := cfg.BatteryThreshold
+	if batteryThreshold == 0 {
+		batteryThreshold = defaultBatteryThresholdPct
+	}
+
+	windows := make(map[string]time.Duration, len(cfg.Entities))
+	for entityID, window := range cfg.Entities {
+		windows[entityID] = window
+	}
+
+	return &Watchdog{
+		connection:       connection,
+		checkInterval:    checkInterval,
+		batteryThreshold: batteryThreshold,
+		staleAfter:       cfg.StaleAfter,
+		windows:          windows,
+		active:           make(map[healthFindingKey]HealthFinding),
+	}
+}
+
+// WithStaleAfter sets how long the given entities may go without reporting
+// before they are flagged, overriding the default from the config.
+func (w *Watchdog) WithStaleAfter(window time.Duration, entities ...EntityInterface) *Watchdog {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, entity := range entities {
+		w.windows[entity.GetID()] = window
+	}
+
+	return w
+}
+
+// WithBatteryThreshold sets the battery percentage below which an entity is
+// flagged.
+func (w *Watchdog) WithBatteryThreshold(percent float64) *Watchdog {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.batteryThreshold = percent
+
+	return w
+}
+
+// OnFinding registers a handler that is called once for every new finding,
+// e.g. to send a notification. It is not called again for the same problem
+// until the entity has recovered. Handlers are serialized with automations.
+// It returns a function that removes the handler.
+func (w *Watchdog) OnFinding(handler func(ctx context.Context, finding HealthFinding)) (remove func()) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.nextListenerID++
+	id := w.nextListenerID
+
+	w.listeners = append(w.listeners, findingListener{id: id, handler: handler})
+
+	return func() {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+
+		w.listeners = slices.DeleteFunc(w.listeners, func(l findingListener) bool {
+			return l.id == id
+		})
+	}
+}
+
+// Findings returns the findings from the latest check, ordered by entity ID.
+func (w *Watchdog) Findings() []HealthFinding {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.sortedFindings()
+}
+
+// Check checks all registered entities now and returns the current findings.
+// Checks also run periodically once the connection is started. It must not be
+// called from an automation or event handler.
+func (w *Watchdog) Check() []HealthFinding {
+	reported := w.fetchLastReported()
+
+	w.connection.mutex.Lock()
+	defer w.connection.mutex.Unlock()
+
+	now := w.connection.getClock().Now()
+
+	w.mutex.Lock()
+
+	current := make(map[healthFindingKey]HealthFinding)
+
+	for _, entity := range w.connection.entities {
+		for _, finding := range w.checkEntity(entity, now, reported[entity.GetID()]) {
+			key := healthFindingKey{entityID: finding.EntityID, kind: finding.Kind}
+
+			if previous, ok := w.active[key]; ok {
+				finding.Since = previous.Since
+			}
+
+			current[key] = finding
+		}
+	}
+
+	var newFindings []HealthFinding
+
+	for key, finding := range current {
+		if _, ok := w.active[key]; !ok {
+			newFindings = append(newFindings, finding)
+		}
+	}
+
+	for key := range w.active {
+		if _, ok := current[key]; !ok {
+			logger.Info("Entity recovered", key.entityID, "problem", key.kind)
+		}
+	}
+
+	w.active = current
+	findings := w.sortedFindings()
+	listeners := slices.Clone(w.listeners)
+
+	w.mutex.Unlock()
+
+	w.record(findings, now)
+
+	slices.SortFunc(newFindings, compareFindings)
+
+	for _, finding := range newFindings {
+		ctx := NewAutomationContext(finding.EntityID, "")
+
+		logger.WarnContext(ctx, "Entity health problem", "problem", finding.Kind, "message", finding.Message)
+
+		for _, listener := range listeners {
+			listener.handler(ctx, finding)
+		}
+	}
+
+	return findings
+}
+
+// fetchLastReported returns when each entity last reported, from Home
+// Assistant. Home Assistant does not send a state change when a sensor reports
+// the same value again, so the states held by entities can be older. It
+// returns nil if the states cannot be fetched, e.g. while disconnected.
+func (w *Watchdog) fetchLastReported() map[string]time.Time {
+	states, err := w.connection.homeAssistant.GetStates()
+	if err != nil {
+		logger.Warn("Error fetching states for health check, using last known states", "", "error", err)
+
+		return nil
+	}
+
+	reported := make(map[string]time.Time, len(states))
+	for _, state := range states {
+		reported[state.EntityID] = lastReportedAt(state)
+	}
+
+	return reported
+}
+
+// lastReportedAt returns when the state was last reported, falling back to when
+// it was last updated for versions of Home Assistant without last_reported.
+func lastReportedAt(state homeassistant.State) time.Time {
+	if state.LastReported.IsZero() {
+		return state.LastUpdated
+	}
+
+	return state.LastReported
+}
+
+// checkEntity returns the problems with an entity, given when Home Assistant
+// says it last reported (zero if unknown). The caller must hold mutex.
+func (w *Watchdog) checkEntity(entity EntityInterface, now, reported time.Time) []HealthFinding {
+	state := entity.GetState()
+
+	// Nothing is known about entities that have not been synced yet.
+	if state.EntityID == "" || state.State == "" {
+		return nil
+	}
+
+	lastReported := lastReportedAt(state)
+	if reported.After(lastReported) {
+		lastReported = reported
+	}
+
+	batteryLevel, hasBattery := getFloat(state.Attributes[batteryLevelAttribute])
+	if !hasBattery {
+		batteryLevel, hasBattery = getFloat(state.Attributes[batteryLevelFallbackAttribute])
+	}
+
+	if !hasBattery && getString(state.Attributes["device_class"]) == batteryDeviceClass {
+		batteryLevel, hasBattery = getFloat(state.State)
+	}
+
+	var battery *float64
+	if hasBattery {
+		battery = &batteryLevel
+	}
+
+	var findings []HealthFinding
+
+	window, ok := w.windows[state.EntityID]
+	if !ok {
+		window = w.staleAfter
+	}
+
+	if window > 0 && !lastReported.IsZero() && now.Sub(lastReported) > window {
+		findings = append(findings, HealthFinding{
+			EntityID:     state.EntityID,
+			Kind:         HealthFindingStale,
+			Message:      fmt.Sprintf("no report for %s (window %s)", now.Sub(lastReported).Truncate(time.Second), window),
+			LastReported: lastReported,
+			BatteryLevel: battery,
+			Since:        now,
+		})
+	}
+
+	if hasBattery && batteryLevel < w.batteryThreshold {
+		findings = append(findings, HealthFinding{
+			EntityID:     state.EntityID,
+			Kind:         HealthFindingLowBattery,
+			Message:      fmt.Sprintf("battery at %v%% (threshold %v%%)", batteryLevel, w.batteryThreshold),
+			LastReported: lastReported,
+			BatteryLevel: battery,
+			Since:        now,
+		})
+	}
+
+	return findings
+}
+
+// record replaces the findings in the store with the given ones.
+func (w *Watchdog) record(findings []HealthFinding, checkedAt time.Time) {
+	rows := make([]store.HealthFinding, len(findings))
+	for i, finding := range findings {
+		rows[i] = store.HealthFinding{
+			EntityID:     finding.EntityID,
+			Kind:         string(finding.Kind),
+			Message:      finding.Message,
+			LastReported: finding.LastReported,
+			BatteryLevel: finding.BatteryLevel,
+			Since:        finding.Since,
+			CheckedAt:    checkedAt,
+		}
+	}
+
+	w.connection.db.EnqueueWrite(func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("1 = 1").Delete(&store.HealthFinding{}).Error; err != nil {
+				return err
+			}
+
+			if len(rows) == 0 {
+				return nil
+			}
+
+			return tx.Create(&rows).Error
+		})
+	})
+}
+
+// run checks entities every check interval until the connection is closed.
+func (w *Watchdog) run() {
+	w.mutex.Lock()
+	interval := w.checkInterval
+	w.mutex.Unlock()
+
+	ticker := w.connection.getClock().Ticker(interval)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.Check()
+		case <-w.connection.shutdownCh:
+			return
+		}
+	}
+}
+
+// sortedFindings returns the active findings ordered by entity ID and kind.
+// The caller must hold mutex.
+func (w *Watchdog) sortedFindings() []HealthFinding {
+	findings := make([]HealthFinding, 0, len(w.active))
+	for _, finding := range w.active {
+		findings = append(findings, finding)
+	}
+
+	slices.SortFunc(findings, compareFindings)
+
+	return findings
+}
+
+func compareFindings(a, b HealthFinding) int {
+	if c := strings.Compare(a.EntityID, b.EntityID); c != 0 {
+		return c
+	}
+
+	return strings.Compare(string(a.Kind), string(b.Kind))
+}
@@ -0,0 +1,190 @@
+package hal_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/dansimau/hal"
+	"github.com/dansimau/hal/homeassistant"
+	"github.com/dansimau/hal/store"
+	"github.com/dansimau/hal/testutil"
+	"github.com/davecgh/go-spew/spew"
+	"gotest.tools/v3/assert"
+)
+
+func TestWatchdog(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "hal.db")
+
+	conn, _, cleanup := testutil.NewClientServerWithConfig(t, hal.Config{
+		DatabasePath: dbPath,
+		Health: hal.HealthConfig{
+			BatteryThreshold: 25,
+			Entities:         map[string]time.Duration{"binary_sensor.door": time.Hour},
+		},
+	})
+	defer cleanup()
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2026, 7, 17, 12, 0, 0, 0, time.UTC))
+	conn.WithClock(mockClock)
+
+	door := hal.NewBinarySensor("binary_sensor.door")
+	motion := hal.NewBinarySensor("binary_sensor.motion")
+	battery := hal.NewNumericSensor("sensor.remote_battery")
+	switch1 := hal.NewSwitch("switch.1")
+	conn.RegisterEntities(door, motion, battery, switch1)
+
+	conn.Watchdog().WithStaleAfter(30*time.Minute, motion)
+
+	var (
+		mutex    sync.Mutex
+		notified []hal.HealthFinding
+	)
+
+	conn.Watchdog().OnFinding(func(_ context.Context, finding hal.HealthFinding) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		notified = append(notified, finding)
+	})
+
+	door.SetState(homeassistant.State{
+		EntityID:     "binary_sensor.door",
+		State:        "off",
+		Attributes:   map[string]any{"battery_level": float64(80)},
+		LastReported: mockClock.Now().Add(-2 * time.Hour),
+	})
+	motion.SetState(homeassistant.State{
+		EntityID:     "binary_sensor.motion",
+		State:        "off",
+		Attributes:   map[string]any{"battery": float64(10)},
+		LastReported: mockClock.Now().Add(-10 * time.Minute),
+	})
+	battery.SetState(homeassistant.State{
+		EntityID:    "sensor.remote_battery",
+		State:       "20",
+		Attributes:  map[string]any{"device_class": "battery", "unit_of_measurement": "%"},
+		LastUpdated: mockClock.Now(),
+	})
+	// Not monitored for staleness, since no window applies to it.
+	switch1.SetState(homeassistant.State{
+		EntityID:     "switch.1",
+		State:        "off",
+		LastReported: mockClock.Now().Add(-24 * time.Hour),
+	})
+
+	findings := conn.Watchdog().Check()
+
+	assert.Equal(t, len(findings), 3)
+	assert.Equal(t, findings[0].EntityID, "binary_sensor.door")
+	assert.Equal(t, findings[0].Kind, hal.HealthFindingStale)
+	assert.Equal(t, findings[0].Message, "no report for 2h0m0s (window 1h0m0s)")
+	assert.Equal(t, *findings[0].BatteryLevel, float64(80))
+	assert.Equal(t, findings[1].EntityID, "binary_sensor.motion")
+	assert.Equal(t, findings[1].Kind, hal.HealthFindingLowBattery)
+	assert.Equal(t, findings[2].EntityID, "sensor.remote_battery")
+	assert.Equal(t, findings[2].Kind, hal.HealthFindingLowBattery)
+
+	// The motion sensor goes stale, which is a new finding. The existing
+	// findings are not notified again.
+	mockClock.Add(time.Hour)
+
+	findings = conn.Watchdog().Check()
+	assert.Equal(t, len(findings), 4)
+
+	mutex.Lock()
+	assert.Equal(t, len(notified), 4)
+	assert.Equal(t, notified[3].EntityID, "binary_sensor.motion")
+	assert.Equal(t, notified[3].Kind, hal.HealthFindingStale)
+	mutex.Unlock()
+
+	// The door reports again, and its finding is cleared.
+	door.SetState(homeassistant.State{
+		EntityID:     "binary_sensor.door",
+		State:        "on",
+		LastReported: mockClock.Now(),
+	})
+
+	findings = conn.Watchdog().Check()
+	assert.Equal(t, len(findings), 3)
+	assert.DeepEqual(t, findings, conn.Watchdog().Findings())
+	assert.Equal(t, findings[0].Since, mockClock.Now().Add(-time.Hour))
+
+	// Findings are recorded in the store.
+	db, err := store.Open(dbPath)
+	assert.NilError(t, err)
+
+	defer db.Close()
+
+	testutil.WaitFor(t, "verify findings recorded", func() bool {
+		var count int64
+		db.Model(&store.HealthFinding{}).Where("entity_id <> ?", "binary_sensor.door").Count(&count)
+
+		var doorCount int64
+		db.Model(&store.HealthFinding{}).Where("entity_id = ?", "binary_sensor.door").Count(&doorCount)
+
+		return count == 3 && doorCount == 0
+	}, func() {
+		var rows []store.HealthFinding
+		db.Find(&rows)
+		spew.Dump(rows)
+	})
+}
+
+func TestWatchdog_SensorReportingSameValue(t *testing.T) {
+	t.Parallel()
+
+	conn, server, cleanup := testutil.NewClientServerWithConfig(t, hal.Config{
+		Health: hal.HealthConfig{StaleAfter: time.Hour},
+	})
+	defer cleanup()
+
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2026, 7, 17, 12, 0, 0, 0, time.UTC))
+	conn.WithClock(mockClock)
+
+	door := hal.NewBinarySensor("binary_sensor.door")
+	conn.RegisterEntities(door)
+
+	server.SendEvent(homeassistant.Event{
+		EventData: homeassistant.EventData{
+			EntityID: door.GetID(),
+			NewState: &homeassistant.State{
+				EntityID:     door.GetID(),
+				State:        "off",
+				LastReported: mockClock.Now(),
+			},
+		},
+	})
+
+	testutil.WaitFor(t, "verify initial state", door.IsOff, func() {
+		spew.Dump(door.GetState())
+	})
+
+	// The sensor keeps reporting "off". Home Assistant only updates
+	// last_reported, without sending a state change.
+	for range 4 {
+		mockClock.Add(30 * time.Minute)
+		server.SetState(homeassistant.State{
+			EntityID:     door.GetID(),
+			State:        "off",
+			LastReported: mockClock.Now(),
+		})
+	}
+
+	assert.Equal(t, len(conn.Watchdog().Check()), 0)
+
+	// It stops reporting.
+	mockClock.Add(2 * time.Hour)
+
+	findings := conn.Watchdog().Check()
+	assert.Equal(t, len(findings), 1)
+	assert.Equal(t, findings[0].Kind, hal.HealthFindingStale)
+	assert.Equal(t, findings[0].LastReported, mockClock.Now().Add(-2*time.Hour))
+}